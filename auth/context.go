@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+type contextKey struct{}
+
+func WithAuthor(ctx context.Context, author model.Author) context.Context {
+	return context.WithValue(ctx, contextKey{}, author)
+}
+
+func AuthorFromContext(ctx context.Context) (model.Author, bool) {
+	author, ok := ctx.Value(contextKey{}).(model.Author)
+	return author, ok
+}
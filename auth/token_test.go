@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenManager(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		tm := NewTokenManager([]byte("secret"), time.Hour)
+
+		token, err := tm.Issue("user1")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		claims, err := tm.Parse(token.Token)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if claims.Subject != "user1" {
+			t.Errorf("Expected subject 'user1', got %s", claims.Subject)
+		}
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		token, _ := NewTokenManager([]byte("secret"), time.Hour).Issue("user1")
+
+		_, err := NewTokenManager([]byte("other"), time.Hour).Parse(token.Token)
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		tm := NewTokenManager([]byte("secret"), time.Hour)
+		token, _ := tm.Issue("user1")
+		other, _ := tm.Issue("admin")
+
+		parts := strings.Split(token.Token, ".")
+		parts[1] = strings.Split(other.Token, ".")[1]
+
+		_, err := tm.Parse(strings.Join(parts, "."))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		tm := NewTokenManager([]byte("secret"), time.Minute)
+		token, _ := tm.Issue("user1")
+
+		tm.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+
+		_, err := tm.Parse(token.Token)
+		if !errors.Is(err, ErrExpiredToken) {
+			t.Errorf("Expected ErrExpiredToken, got %v", err)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		tm := NewTokenManager([]byte("secret"), time.Hour)
+
+		_, err := tm.Parse("not-a-token")
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	})
+}
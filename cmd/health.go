@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/juanplagos/bubble/handler"
+	"github.com/juanplagos/bubble/repository"
+)
+
+// postgresChecks keep the server ready while the database answers and its
+// schema is the one this binary was built for.
+func postgresChecks(pool *pgxpool.Pool, migrator *repository.Migrator) []handler.HealthCheck {
+	return []handler.HealthCheck{
+		{Name: "database", Check: pool.Ping},
+		{Name: "migrations", Check: func(ctx context.Context) error {
+			pending, err := migrator.Pending(ctx)
+			if err != nil || len(pending) == 0 {
+				return err
+			}
+			names := make([]string, len(pending))
+			for i, mig := range pending {
+				names[i] = mig.String()
+			}
+			return fmt.Errorf("%d pending: %s", len(pending), strings.Join(names, ", "))
+		}},
+	}
+}
@@ -1,31 +1,132 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
-	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/auth"
+	"github.com/juanplagos/bubble/config"
+	"github.com/juanplagos/bubble/event"
+	"github.com/juanplagos/bubble/handler"
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/metrics"
+	"github.com/juanplagos/bubble/model"
 	"github.com/juanplagos/bubble/router"
+	"github.com/juanplagos/bubble/storage"
+	"github.com/juanplagos/bubble/usecase"
 	"github.com/rs/cors"
 )
 
 func main() {
-	pool := repository.InitPostgresPool()
-	defer pool.Close()
-	mux := router.RegisterRoutes(pool)
+	logger := logging.New(os.Stderr, slog.LevelInfo, "json")
+
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fatal(logger, "configuração inválida", err)
+	}
+
+	logger = logging.New(os.Stderr, cfg.Log.SlogLevel(), cfg.Log.Format)
+	slog.SetDefault(logger)
+
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(cfg, args[1:], logger); err != nil {
+			fatal(logger, "migrate falhou", err)
+		}
+		return
+	}
+	if len(args) > 0 {
+		fatal(logger, "comando desconhecido", errors.New(args[0]))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fatal(logger, "configuração inválida", err)
+	}
+	logger.Info("configuração efetiva", "config", cfg.String())
+
+	files, err := storage.NewLocal(cfg.Media.Dir)
+	if err != nil {
+		fatal(logger, "MEDIA_DIR inválido", err)
+	}
+
+	reg := metrics.NewRegistry()
+	repos, checks, closeStore, err := openStore(context.Background(), cfg.Database, reg, logger)
+	if err != nil {
+		fatal(logger, "não foi possível abrir o banco de dados", err)
+	}
+	health := handler.NewHealthHandler(cfg.Server.ReadinessTimeout, checks...)
+
+	events := event.NewBus()
+	registerDomainMetrics(reg, events)
+	events.Subscribe(event.EntryPublished, func(e event.Event) {
+		if entry, ok := e.Payload.(model.Entry); ok {
+			logger.Info("entrada publicada", "entry_id", entry.ID, "slug", entry.Slug)
+		}
+	})
+
+	// After the first signal the default handling comes back, so a second
+	// one kills the process without waiting for the drain.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+	context.AfterFunc(ctx, health.Drain)
+
+	workers, stopWorkers := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	scheduled := usecase.NewEntryUseCase(repos.Entries, events, logger)
+	wg.Go(func() { runScheduler(workers, scheduled, cfg.Scheduler.PublishInterval, logger) })
+
+	feeds := handler.FeedConfig{
+		BaseURL:     cfg.Site.BaseURL,
+		Title:       cfg.Site.Title,
+		Description: cfg.Site.Description,
+	}
+	tokens := auth.NewTokenManager([]byte(cfg.Auth.Secret), cfg.Auth.TokenTTL)
+	mux := router.RegisterRoutes(repos, tokens, events, feeds, files, cfg.Server.RequestTimeout, logger, reg, health)
 
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{os.Getenv("ALLOWED_ORIGIN")},
-        AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowedHeaders:   []string{"*"},
-        AllowCredentials: true,
+		AllowedOrigins:   []string{cfg.Server.AllowedOrigin},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
 	})
 
-	handler := c.Handler(mux)
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           handler.RequestID(handler.AccessLog(logger, handler.Instrument(reg, c.Handler(mux)))),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
 
-	err := http.ListenAndServe(":8080", handler)
+	err = serve(ctx, server, cfg.Server.DrainDelay, cfg.Server.ShutdownGrace, logger)
 	if err != nil {
-	    log.Fatal(err)
+		logger.Error("servidor encerrado com erro", "error", err)
 	}
-}
\ No newline at end of file
+
+	stopWorkers()
+	wg.Wait()
+	closeStore()
+
+	if err != nil {
+		os.Exit(1)
+	}
+	logger.Info("servidor encerrado")
+}
+
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
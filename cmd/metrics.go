@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/juanplagos/bubble/event"
+	"github.com/juanplagos/bubble/metrics"
+)
+
+// registerDomainMetrics counts what the usecases report on the event bus.
+func registerDomainMetrics(reg *metrics.Registry, events *event.Bus) {
+	counters := []struct {
+		event event.Name
+		name  string
+		help  string
+	}{
+		{event.EntryCreated, "bubble_entries_created_total", "Entries created."},
+		{event.EntryPublished, "bubble_entries_published_total", "Entries published, directly or on schedule."},
+		{event.CommentCreated, "bubble_comments_created_total", "Comments received, whatever their status."},
+		{event.CommentApproved, "bubble_comments_approved_total", "Comments approved, automatically or by a moderator."},
+		{event.LoginFailed, "bubble_logins_failed_total", "Login attempts with wrong credentials."},
+	}
+
+	for _, c := range counters {
+		counter := reg.NewCounter(c.name, c.help)
+		events.Subscribe(c.event, func(event.Event) { counter.Inc() })
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/juanplagos/bubble/config"
+	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/repository/migrations"
+)
+
+var errUsage = errors.New("uso: migrate up | down [-steps n] | status | baseline -version n")
+
+// runMigrate handles `migrate up|down|status|baseline`. down reverts one
+// migration unless -steps says otherwise; baseline marks a database created
+// from the old repository/schema.sql as migrated up to -version.
+func runMigrate(cfg config.Config, args []string, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "quantas migrações reverter com down")
+	version := flags.Int("version", 0, "até qual versão baseline marca como aplicada")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), errUsage)
+		flags.PrintDefaults()
+	}
+
+	if len(args) == 0 {
+		return errUsage
+	}
+	command := args[0]
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *steps < 1 {
+		return fmt.Errorf("-steps inválido: %d", *steps)
+	}
+
+	if err := cfg.ValidateDatabase(); err != nil {
+		return fmt.Errorf("configuração inválida:\n%w", err)
+	}
+
+	ctx := context.Background()
+	pool, err := repository.InitPostgresPool(ctx, cfg.Database.ConnString(), logger)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	migrator, err := repository.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		return fmt.Errorf("migrações inválidas: %w", err)
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			logger.Info("migração aplicada", "migration", m.String())
+		}
+		if err != nil {
+			return fmt.Errorf("não foi possível aplicar as migrações: %w", err)
+		}
+		if len(applied) == 0 {
+			logger.Info("nenhuma migração pendente")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, m := range reverted {
+			logger.Info("migração revertida", "migration", m.String())
+		}
+		if err != nil {
+			return fmt.Errorf("não foi possível reverter as migrações: %w", err)
+		}
+	case "baseline":
+		if *version < 1 {
+			return fmt.Errorf("-version inválido: %d", *version)
+		}
+		recorded, err := migrator.Baseline(ctx, *version)
+		if err != nil {
+			return fmt.Errorf("não foi possível registrar as migrações: %w", err)
+		}
+		for _, m := range recorded {
+			logger.Info("migração registrada", "migration", m.String())
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("não foi possível ler o estado das migrações: %w", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSÃO\tNOME\tAPLICADA EM")
+		for _, s := range statuses {
+			applied := "pendente"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		w.Flush()
+	default:
+		return errUsage
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/juanplagos/bubble/usecase"
+)
+
+// runScheduler publishes scheduled entries whose time has come, once at
+// startup and then every interval, until ctx is cancelled.
+func runScheduler(ctx context.Context, entries usecase.EntryUseCase, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := entries.PublishScheduledEntries(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("não foi possível publicar entradas agendadas", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
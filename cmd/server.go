@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// serve runs server until ctx is cancelled. It then keeps accepting
+// connections for drain, while readiness fails and the orchestrator stops
+// routing here, stops accepting them and gives in-flight requests up to
+// grace to finish before cutting them off.
+func serve(ctx context.Context, server *http.Server, drain, grace time.Duration, logger *slog.Logger) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.ListenAndServe()
+	}()
+	logger.Info("ouvindo", "addr", server.Addr)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	if drain > 0 {
+		logger.Info("encerrando: aguardando a saída do balanceamento", "drain", drain.String())
+		select {
+		case err := <-errc:
+			return err
+		case <-time.After(drain):
+		}
+	}
+
+	logger.Info("encerrando: aguardando requisições em andamento", "grace", grace.String())
+	shutdown, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	if err := server.Shutdown(shutdown); err != nil {
+		server.Close()
+		return fmt.Errorf("requisições ainda em andamento após %s: %w", grace, err)
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/juanplagos/bubble/config"
+	"github.com/juanplagos/bubble/handler"
+	"github.com/juanplagos/bubble/metrics"
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/repository/migrations"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+// openStore sets up where the data lives, returning its repositories, the
+// readiness checks that go with it and a function releasing it once the
+// server is done.
+func openStore(ctx context.Context, cfg config.Database, reg *metrics.Registry, logger *slog.Logger) (repository.Repos, []handler.HealthCheck, func(), error) {
+	if cfg.InMemory() {
+		repos := repository.NewMemoryRepos(repository.NewMemoryStore(logger))
+		if err := seedAdmin(ctx, repos.Authors, cfg, logger); err != nil {
+			return repository.Repos{}, nil, nil, err
+		}
+		logger.Warn("dados em memória: nada será persistido e comentários e mídia ficam indisponíveis")
+		return repos, nil, func() {}, nil
+	}
+
+	pool, err := repository.InitPostgresPool(ctx, cfg.ConnString(), logger)
+	if err != nil {
+		return repository.Repos{}, nil, nil, err
+	}
+	migrator, err := repository.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		pool.Close()
+		return repository.Repos{}, nil, nil, err
+	}
+
+	repos := repository.NewPostgresRepos(pool)
+	if cfg.SeedPassword != "" {
+		if err := seedAdmin(ctx, repos.Authors, cfg, logger); err != nil {
+			pool.Close()
+			return repository.Repos{}, nil, nil, err
+		}
+	}
+
+	repository.RegisterPoolMetrics(reg, pool)
+	return repos, postgresChecks(pool, migrator), pool.Close, nil
+}
+
+// seedAdmin creates the seed admin unless an author already has its
+// username, going through the usecase so the password is checked and
+// hashed like any other. Several replicas may race to create it; whichever
+// loses finds it already there.
+func seedAdmin(ctx context.Context, authors repository.AuthorRepo, cfg config.Database, logger *slog.Logger) error {
+	existing, err := authors.GetAuthorByUsername(ctx, cfg.SeedUsername)
+	if err == nil {
+		if existing.Role != model.RoleAdmin {
+			logger.Warn("o admin inicial já existe sem o papel de admin e não foi alterado", "username", existing.Username, "role", existing.Role)
+		}
+		return nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("procurando o admin %q: %w", cfg.SeedUsername, err)
+	}
+
+	admin := model.Author{
+		Username: cfg.SeedUsername,
+		Email:    cfg.SeedUsername + "@localhost",
+		Password: cfg.SeedPassword,
+		Role:     model.RoleAdmin,
+	}
+	err = usecase.NewAuthorUseCase(authors, logger).CreateAuthor(ctx, model.Author{Role: model.RoleAdmin}, &admin)
+	if errors.Is(err, repository.ErrConflict) {
+		if _, lookupErr := authors.GetAuthorByUsername(ctx, cfg.SeedUsername); lookupErr == nil {
+			return nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("criando o admin %q: %w", cfg.SeedUsername, err)
+	}
+
+	logger.Info("admin inicial criado", "username", admin.Username)
+	return nil
+}
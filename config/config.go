@@ -0,0 +1,470 @@
+// Package config loads the server's settings. Defaults are overridden by a
+// YAML file, then by environment variables and finally by command-line
+// flags, so a deployment can keep a shared file and still adjust a single
+// value per environment.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "xxxxx"
+
+const (
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	Auth      Auth      `yaml:"auth"`
+	Site      Site      `yaml:"site"`
+	Media     Media     `yaml:"media"`
+	Scheduler Scheduler `yaml:"scheduler"`
+	Log       Log       `yaml:"log"`
+}
+
+type Server struct {
+	Addr              string        `yaml:"addr"`
+	AllowedOrigin     string        `yaml:"allowed_origin"`
+	RequestTimeout    time.Duration `yaml:"request_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes"`
+	ShutdownGrace     time.Duration `yaml:"shutdown_grace"`
+	DrainDelay        time.Duration `yaml:"drain_delay"`
+	ReadinessTimeout  time.Duration `yaml:"readiness_timeout"`
+}
+
+// Database is either a URL or its parts. SSLMode and the pool sizes apply
+// to both and take precedence over the same settings in the URL. The
+// memory driver needs none of them: nothing is persisted, and the seed
+// admin is the only author when the server starts.
+//
+// With SeedPassword set, the server creates the seed admin at startup if
+// no author has its username, which is how a new Postgres deployment gets
+// its first admin. Existing authors are never changed, so the password can
+// be removed from the configuration once the admin has logged in.
+type Database struct {
+	Driver   string `yaml:"driver"`
+	URL      string `yaml:"url"`
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+	MaxConns int    `yaml:"max_conns"`
+	MinConns int    `yaml:"min_conns"`
+
+	SeedUsername string `yaml:"seed_username"`
+	SeedPassword string `yaml:"seed_password"`
+}
+
+// InMemory reports whether the server keeps its data in memory instead of
+// Postgres.
+func (d Database) InMemory() bool {
+	return d.Driver == DriverMemory
+}
+
+type Auth struct {
+	Secret   string        `yaml:"secret"`
+	TokenTTL time.Duration `yaml:"token_ttl"`
+}
+
+type Site struct {
+	BaseURL     string `yaml:"base_url"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+}
+
+type Media struct {
+	Dir string `yaml:"dir"`
+}
+
+type Scheduler struct {
+	PublishInterval time.Duration `yaml:"publish_interval"`
+}
+
+type Log struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// SlogLevel is Level as understood by log/slog, or info when it is not a
+// valid level.
+func (l Log) SlogLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+var sslModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true,
+	"require": true, "verify-ca": true, "verify-full": true,
+}
+
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:              ":8080",
+			RequestTimeout:    15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			// Uploads must arrive within ReadTimeout, so it is sized for
+			// the largest file over a modest connection.
+			ReadTimeout:    time.Minute,
+			WriteTimeout:   time.Minute,
+			IdleTimeout:    2 * time.Minute,
+			MaxHeaderBytes: 64 << 10,
+			ShutdownGrace:  30 * time.Second,
+			// Deployments should cover their readiness probe period; off
+			// by default so a local server stops at once.
+			DrainDelay:       0,
+			ReadinessTimeout: 2 * time.Second,
+		},
+		Database: Database{
+			Driver:       DriverPostgres,
+			Host:         "localhost",
+			Port:         "5432",
+			SeedUsername: "admin",
+		},
+		Auth: Auth{
+			TokenTTL: 24 * time.Hour,
+		},
+		Site: Site{
+			BaseURL: "http://localhost:8080",
+			Title:   "bubble",
+		},
+		Media: Media{
+			Dir: "media",
+		},
+		Scheduler: Scheduler{
+			PublishInterval: time.Minute,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+}
+
+// setting ties a value to its flag, which is named after its path in the
+// file, and to its environment variable.
+type setting struct {
+	key   string
+	env   string
+	usage string
+	value any
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"server.addr", "ADDR", "endereço em que o servidor escuta", &c.Server.Addr},
+		{"server.allowed_origin", "ALLOWED_ORIGIN", "origem aceita pelo CORS", &c.Server.AllowedOrigin},
+		{"server.request_timeout", "REQUEST_TIMEOUT", "prazo de cada requisição; 0 desativa", &c.Server.RequestTimeout},
+		{"server.read_header_timeout", "READ_HEADER_TIMEOUT", "prazo para ler os cabeçalhos", &c.Server.ReadHeaderTimeout},
+		{"server.read_timeout", "READ_TIMEOUT", "prazo para ler a requisição inteira", &c.Server.ReadTimeout},
+		{"server.write_timeout", "WRITE_TIMEOUT", "prazo para escrever a resposta", &c.Server.WriteTimeout},
+		{"server.idle_timeout", "IDLE_TIMEOUT", "tempo máximo de uma conexão ociosa", &c.Server.IdleTimeout},
+		{"server.max_header_bytes", "MAX_HEADER_BYTES", "tamanho máximo dos cabeçalhos", &c.Server.MaxHeaderBytes},
+		{"server.shutdown_grace", "SHUTDOWN_GRACE", "espera pelas requisições em andamento ao encerrar", &c.Server.ShutdownGrace},
+		{"server.drain_delay", "DRAIN_DELAY", "tempo com /readyz falhando antes de parar de aceitar conexões", &c.Server.DrainDelay},
+		{"server.readiness_timeout", "READINESS_TIMEOUT", "prazo das verificações do /readyz", &c.Server.ReadinessTimeout},
+		{"database.driver", "DB_DRIVER", "onde os dados ficam: postgres ou memory, que não persiste nada", &c.Database.Driver},
+		{"database.url", "DATABASE_URL", "URL do postgres; substitui host, porta, usuário, senha e banco", &c.Database.URL},
+		{"database.host", "POSTGRES_HOST", "host do postgres", &c.Database.Host},
+		{"database.port", "POSTGRES_PORT", "porta do postgres", &c.Database.Port},
+		{"database.user", "POSTGRES_USER", "usuário do postgres", &c.Database.User},
+		{"database.password", "POSTGRES_PASSWORD", "senha do postgres", &c.Database.Password},
+		{"database.name", "POSTGRES_DB", "banco do postgres", &c.Database.Name},
+		{"database.sslmode", "POSTGRES_SSLMODE", "sslmode da conexão", &c.Database.SSLMode},
+		{"database.max_conns", "DB_MAX_CONNS", "máximo de conexões na pool; 0 usa o padrão do pgx", &c.Database.MaxConns},
+		{"database.min_conns", "DB_MIN_CONNS", "conexões mantidas abertas na pool", &c.Database.MinConns},
+		{"database.seed_username", "DB_SEED_USERNAME", "admin criado ao iniciar se ainda não existir", &c.Database.SeedUsername},
+		{"database.seed_password", "DB_SEED_PASSWORD", "senha do admin criado ao iniciar; vazio não cria nenhum, exceto com o driver memory", &c.Database.SeedPassword},
+		{"auth.secret", "AUTH_SECRET", "chave que assina os tokens", &c.Auth.Secret},
+		{"auth.token_ttl", "AUTH_TOKEN_TTL", "validade dos tokens", &c.Auth.TokenTTL},
+		{"site.base_url", "BASE_URL", "URL pública do site", &c.Site.BaseURL},
+		{"site.title", "SITE_TITLE", "título do site nos feeds", &c.Site.Title},
+		{"site.description", "SITE_DESCRIPTION", "descrição do site nos feeds", &c.Site.Description},
+		{"media.dir", "MEDIA_DIR", "diretório dos arquivos enviados", &c.Media.Dir},
+		{"scheduler.publish_interval", "PUBLISH_INTERVAL", "intervalo entre publicações agendadas", &c.Scheduler.PublishInterval},
+		{"log.level", "LOG_LEVEL", "nível mínimo dos logs: debug, info, warn ou error", &c.Log.Level},
+		{"log.format", "LOG_FORMAT", "formato dos logs: json ou text", &c.Log.Format},
+	}
+}
+
+// Load builds the configuration from the file named by -config or
+// CONFIG_FILE, the environment and args, returning the arguments left
+// after the flags. It does not validate the result.
+func Load(args []string) (Config, []string, error) {
+	path := os.Getenv("CONFIG_FILE")
+
+	// A first pass only finds -config; the file has to be read before the
+	// flags are applied on top of it.
+	scratch := Default()
+	fs, err := scratch.flagSet(&path)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil && !errors.Is(err, flag.ErrHelp) {
+		return Config{}, nil, err
+	}
+
+	cfg := Default()
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, nil, err
+		}
+	}
+
+	fs, err = cfg.flagSet(&path)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	for _, s := range cfg.settings() {
+		raw, ok := os.LookupEnv(s.env)
+		if !ok || raw == "" {
+			continue
+		}
+		if err := fs.Set(s.key, raw); err != nil {
+			return Config{}, nil, fmt.Errorf("%s inválido: %q", s.env, raw)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// flagSet registers a flag per setting, defaulting to its current value.
+func (c *Config) flagSet(path *string) (*flag.FlagSet, error) {
+	fs := flag.NewFlagSet("bubble", flag.ContinueOnError)
+	fs.StringVar(path, "config", *path, "arquivo de configuração YAML (CONFIG_FILE)")
+
+	for _, s := range c.settings() {
+		usage := s.usage + " (" + s.env + ")"
+		switch v := s.value.(type) {
+		case *string:
+			fs.StringVar(v, s.key, *v, usage)
+		case *int:
+			fs.IntVar(v, s.key, *v, usage)
+		case *time.Duration:
+			fs.DurationVar(v, s.key, *v, usage)
+		default:
+			return nil, fmt.Errorf("config: %s has unsupported type %T", s.key, s.value)
+		}
+	}
+
+	// Defaults may come from the environment; -h must not print secrets.
+	safe := c.Redacted()
+	fs.Lookup("database.url").DefValue = safe.Database.URL
+	fs.Lookup("database.password").DefValue = safe.Database.Password
+	fs.Lookup("database.seed_password").DefValue = safe.Database.SeedPassword
+	fs.Lookup("auth.secret").DefValue = safe.Auth.Secret
+	return fs, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+	default:
+		return fmt.Errorf("%s: formato de configuração não suportado, use YAML", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once, naming each by its key
+// and environment variable.
+func (c Config) Validate() error {
+	v := &validator{c: &c}
+
+	v.check(c.Server.Addr != "", &c.Server.Addr, "obrigatório")
+	v.check(c.Server.RequestTimeout >= 0, &c.Server.RequestTimeout, "não pode ser negativo")
+	v.check(c.Server.ReadHeaderTimeout >= 0, &c.Server.ReadHeaderTimeout, "não pode ser negativo")
+	v.check(c.Server.ReadTimeout >= 0, &c.Server.ReadTimeout, "não pode ser negativo")
+	v.check(c.Server.WriteTimeout >= 0, &c.Server.WriteTimeout, "não pode ser negativo")
+	v.check(c.Server.IdleTimeout >= 0, &c.Server.IdleTimeout, "não pode ser negativo")
+	v.check(c.Server.MaxHeaderBytes > 0, &c.Server.MaxHeaderBytes, "deve ser positivo")
+	v.check(c.Server.ShutdownGrace >= 0, &c.Server.ShutdownGrace, "não pode ser negativo")
+	v.check(c.Server.DrainDelay >= 0, &c.Server.DrainDelay, "não pode ser negativo")
+	v.check(c.Server.ReadinessTimeout > 0, &c.Server.ReadinessTimeout, "deve ser positivo")
+
+	if c.Database.InMemory() {
+		v.check(c.Database.SeedUsername != "", &c.Database.SeedUsername, "obrigatório com o driver memory")
+		v.check(c.Database.SeedPassword != "", &c.Database.SeedPassword, "obrigatório com o driver memory")
+	} else {
+		v.database()
+	}
+
+	v.check(c.Auth.Secret != "", &c.Auth.Secret, "obrigatório")
+	v.check(c.Auth.TokenTTL > 0, &c.Auth.TokenTTL, "deve ser positivo")
+
+	base, err := url.Parse(c.Site.BaseURL)
+	v.check(err == nil && base.IsAbs() && base.Host != "", &c.Site.BaseURL, "deve ser uma URL absoluta")
+	v.check(c.Media.Dir != "", &c.Media.Dir, "obrigatório")
+	v.check(c.Scheduler.PublishInterval > 0, &c.Scheduler.PublishInterval, "deve ser positivo")
+
+	var level slog.Level
+	v.check(level.UnmarshalText([]byte(c.Log.Level)) == nil, &c.Log.Level, "deve ser debug, info, warn ou error")
+	v.check(c.Log.Format == "json" || c.Log.Format == "text", &c.Log.Format, "deve ser json ou text")
+
+	return errors.Join(v.errs...)
+}
+
+// ValidateDatabase checks only what is needed to connect, for commands
+// such as migrate that never serve requests.
+func (c Config) ValidateDatabase() error {
+	v := &validator{c: &c}
+	v.check(!c.Database.InMemory(), &c.Database.Driver, "deve ser postgres")
+	v.database()
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	c    *Config
+	errs []error
+}
+
+// check records message against the setting whose value field points to
+// when ok is false.
+func (v *validator) check(ok bool, field any, message string) {
+	if ok {
+		return
+	}
+	for _, s := range v.c.settings() {
+		if s.value == field {
+			v.errs = append(v.errs, fmt.Errorf("%s (%s): %s", s.key, s.env, message))
+			return
+		}
+	}
+	panic("config: validating an unknown setting")
+}
+
+func (v *validator) database() {
+	d := &v.c.Database
+	v.check(d.Driver == "" || d.Driver == DriverPostgres || d.Driver == DriverMemory, &d.Driver, "deve ser postgres ou memory")
+	if d.URL != "" {
+		u, err := url.Parse(d.URL)
+		v.check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql"), &d.URL, "deve ser uma URL postgres://")
+	} else {
+		v.check(d.Host != "", &d.Host, "obrigatório sem DATABASE_URL")
+		v.check(d.User != "", &d.User, "obrigatório sem DATABASE_URL")
+		v.check(d.Name != "", &d.Name, "obrigatório sem DATABASE_URL")
+		_, err := strconv.ParseUint(d.Port, 10, 16)
+		v.check(err == nil, &d.Port, "deve ser uma porta")
+	}
+	v.check(d.SSLMode == "" || sslModes[d.SSLMode], &d.SSLMode, "deve ser disable, allow, prefer, require, verify-ca ou verify-full")
+	v.check(d.MaxConns >= 0, &d.MaxConns, "não pode ser negativo")
+	v.check(d.SeedPassword == "" || d.SeedUsername != "", &d.SeedUsername, "obrigatório com database.seed_password")
+	v.check(d.MinConns >= 0 && (d.MaxConns == 0 || d.MinConns <= d.MaxConns), &d.MinConns, "deve estar entre 0 e database.max_conns")
+}
+
+// ConnString is the connection string pgxpool expects, pool sizes
+// included.
+func (d Database) ConnString() string {
+	u, err := url.Parse(d.URL)
+	if d.URL == "" || err != nil {
+		u = &url.URL{
+			Scheme: "postgres",
+			User:   url.UserPassword(d.User, d.Password),
+			Host:   net.JoinHostPort(d.Host, d.Port),
+			Path:   "/" + d.Name,
+		}
+	}
+
+	q := u.Query()
+	if d.SSLMode != "" {
+		q.Set("sslmode", d.SSLMode)
+	}
+	if d.MaxConns > 0 {
+		q.Set("pool_max_conns", strconv.Itoa(d.MaxConns))
+	}
+	if d.MinConns > 0 {
+		q.Set("pool_min_conns", strconv.Itoa(d.MinConns))
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// Redacted returns a copy of c that is safe to log.
+func (c Config) Redacted() Config {
+	c.Database.URL = redactConnString(c.Database.URL)
+	if c.Database.Password != "" {
+		c.Database.Password = redacted
+	}
+	if c.Database.SeedPassword != "" {
+		c.Database.SeedPassword = redacted
+	}
+	if c.Auth.Secret != "" {
+		c.Auth.Secret = redacted
+	}
+	return c
+}
+
+// dsnPassword matches the password of a key/value connection string, quoted
+// or not.
+var dsnPassword = regexp.MustCompile(`(?i)(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// redactConnString hides the passwords a connection string can carry: the
+// URL's userinfo and password parameter, or the password key of the
+// key/value form pgx also accepts.
+func redactConnString(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	if u.Scheme == "" {
+		return dsnPassword.ReplaceAllString(raw, "${1}"+redacted)
+	}
+
+	if q := u.Query(); q.Has("password") {
+		q.Set("password", redacted)
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
+}
+
+// String renders the effective configuration as YAML, secrets redacted.
+func (c Config) String() string {
+	out, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, args, err := Load(nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Server.Addr != ":8080" || cfg.Auth.TokenTTL != 24*time.Hour || len(args) != 0 {
+			t.Errorf("Expected defaults, got %+v", cfg)
+		}
+	})
+
+	t.Run("flags over env over file", func(t *testing.T) {
+		path := writeFile(t, "bubble.yaml", `
+server:
+  addr: ":9000"
+  request_timeout: 5s
+database:
+  name: file
+  user: file
+site:
+  title: file
+`)
+		t.Setenv("POSTGRES_DB", "env")
+		t.Setenv("SITE_TITLE", "env")
+
+		cfg, args, err := Load([]string{"-config", path, "-site.title", "flag", "migrate", "up"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if cfg.Server.Addr != ":9000" || cfg.Server.RequestTimeout != 5*time.Second || cfg.Database.User != "file" {
+			t.Errorf("Expected file values, got %+v", cfg.Server)
+		}
+		if cfg.Database.Name != "env" {
+			t.Errorf("Expected env to override the file, got %q", cfg.Database.Name)
+		}
+		if cfg.Site.Title != "flag" {
+			t.Errorf("Expected flag to override env, got %q", cfg.Site.Title)
+		}
+		if strings.Join(args, " ") != "migrate up" {
+			t.Errorf("Expected remaining args, got %v", args)
+		}
+	})
+
+	t.Run("config file from env", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", writeFile(t, "bubble.yml", "media:\n  dir: /srv/media\n"))
+
+		cfg, _, err := Load(nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Media.Dir != "/srv/media" {
+			t.Errorf("Expected file value, got %q", cfg.Media.Dir)
+		}
+	})
+
+	t.Run("unknown key in file", func(t *testing.T) {
+		path := writeFile(t, "bubble.yaml", "server:\n  adress: \":9000\"\n")
+		if _, _, err := Load([]string{"-config", path}); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		path := writeFile(t, "bubble.toml", "")
+		if _, _, err := Load([]string{"-config", path}); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+
+	t.Run("invalid env value", func(t *testing.T) {
+		t.Setenv("AUTH_TOKEN_TTL", "forever")
+		_, _, err := Load(nil)
+		if err == nil || !strings.Contains(err.Error(), "AUTH_TOKEN_TTL") {
+			t.Errorf("Expected an error naming the variable, got %v", err)
+		}
+	})
+}
+
+func validConfig() Config {
+	cfg := Default()
+	cfg.Auth.Secret = "secret"
+	cfg.Database.User = "bubble"
+	cfg.Database.Password = "hunter2"
+	cfg.Database.Name = "bubble"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Expected a valid config, got %v", err)
+	}
+
+	cfg := validConfig()
+	cfg.Auth.Secret = ""
+	cfg.Database.SSLMode = "sometimes"
+	cfg.Database.MaxConns, cfg.Database.MinConns = 2, 5
+	cfg.Scheduler.PublishInterval = 0
+	cfg.Log.Format = "xml"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	for _, want := range []string{"auth.secret (AUTH_SECRET)", "database.sslmode", "database.min_conns", "scheduler.publish_interval", "log.format (LOG_FORMAT)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q to be reported, got:\n%v", want, err)
+		}
+	}
+
+	if err := cfg.ValidateDatabase(); err == nil || strings.Contains(err.Error(), "auth.secret") {
+		t.Errorf("Expected only database errors, got %v", err)
+	}
+
+	t.Run("url replaces parts", func(t *testing.T) {
+		cfg := validConfig()
+		cfg.Database = Database{URL: "postgres://bubble@db/bubble"}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected a valid config, got %v", err)
+		}
+	})
+
+	t.Run("memory driver needs no database", func(t *testing.T) {
+		cfg := Default()
+		cfg.Auth.Secret = "secret"
+		cfg.Database.Driver = DriverMemory
+		cfg.Database.Host = ""
+
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "database.seed_password (DB_SEED_PASSWORD)") || strings.Contains(err.Error(), "database.host") {
+			t.Errorf("Expected only the seed password to be reported, got %v", err)
+		}
+
+		cfg.Database.SeedPassword = "correct horse battery"
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected a valid config, got %v", err)
+		}
+		if err := cfg.ValidateDatabase(); err == nil {
+			t.Error("Expected migrate to refuse the memory driver")
+		}
+	})
+}
+
+func TestConnString(t *testing.T) {
+	d := Database{Host: "db", Port: "5432", User: "bubble", Password: "p@ss word", Name: "blog", SSLMode: "require", MaxConns: 10}
+	want := "postgres://bubble:p%40ss%20word@db:5432/blog?pool_max_conns=10&sslmode=require"
+	if got := d.ConnString(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	d = Database{URL: "postgres://u:p@db/blog?sslmode=disable", SSLMode: "verify-full"}
+	want = "postgres://u:p@db/blog?sslmode=verify-full"
+	if got := d.ConnString(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"postgres://bubble:topsecret@db/blog", "postgres://bubble:xxxxx@db/blog"},
+		{"postgres://db/blog?user=bubble&password=topsecret", "postgres://db/blog?password=xxxxx&user=bubble"},
+		{"host=db user=bubble password=topsecret dbname=blog", "host=db user=bubble password=xxxxx dbname=blog"},
+		{"host=db password = 'top secret\\' too' dbname=blog", "host=db password = xxxxx dbname=blog"},
+		{"postgres://db/blog", "postgres://db/blog"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		cfg := validConfig()
+		cfg.Database.URL = tt.url
+		if got := cfg.Redacted().Database.URL; got != tt.want {
+			t.Errorf("Redacting %q: expected %q, got %q", tt.url, tt.want, got)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	cfg := validConfig()
+	cfg.Database.URL = "postgres://bubble:topsecret@db/bubble"
+	cfg.Database.SeedPassword = "letmein"
+
+	out := cfg.String()
+	for _, secret := range []string{"hunter2", "topsecret", "letmein", "secret: secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Expected %q to be redacted:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "request_timeout: 15s") {
+		t.Errorf("Expected durations to be readable:\n%s", out)
+	}
+	if cfg.Auth.Secret != "secret" {
+		t.Error("Expected the original config to be left alone")
+	}
+}
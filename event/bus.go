@@ -0,0 +1,58 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+type Name string
+
+const (
+	EntryCreated    Name = "entry.created"
+	EntryPublished  Name = "entry.published"
+	CommentCreated  Name = "comment.created"
+	CommentApproved Name = "comment.approved"
+	LoginFailed     Name = "auth.login_failed"
+)
+
+type Event struct {
+	Name    Name
+	Payload any
+	At      time.Time
+}
+
+type Handler func(Event)
+
+// Bus delivers events synchronously to every handler subscribed to their
+// name. A nil *Bus drops everything, which keeps it optional for callers.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Name][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[Name][]Handler),
+	}
+}
+
+func (b *Bus) Subscribe(name Name, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+func (b *Bus) Publish(name Name, payload any) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers[name]
+	b.mu.RUnlock()
+
+	e := Event{Name: name, Payload: payload, At: time.Now()}
+	for _, h := range handlers {
+		h(e)
+	}
+}
@@ -0,0 +1,25 @@
+package event
+
+import "testing"
+
+func TestBus(t *testing.T) {
+	t.Run("delivers to subscribers of the name", func(t *testing.T) {
+		bus := NewBus()
+
+		var got []any
+		bus.Subscribe(EntryPublished, func(e Event) { got = append(got, e.Payload) })
+		bus.Subscribe("other", func(e Event) { t.Error("Expected other handlers not to be called") })
+
+		bus.Publish(EntryPublished, 1)
+		bus.Publish(EntryPublished, 2)
+
+		if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+			t.Errorf("Expected [1 2], got %v", got)
+		}
+	})
+
+	t.Run("nil bus", func(t *testing.T) {
+		var bus *Bus
+		bus.Publish(EntryPublished, 1)
+	})
+}
@@ -0,0 +1,261 @@
+// Package feed encodes lists of entries as RSS 2.0, Atom and JSON Feed
+// documents.
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+type Format string
+
+const (
+	RSS  Format = "rss"
+	Atom Format = "atom"
+	JSON Format = "json"
+)
+
+// Files maps the file names feeds are served under to their format.
+var Files = map[string]Format{
+	"feed.xml":  RSS,
+	"atom.xml":  Atom,
+	"feed.json": JSON,
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case RSS:
+		return "application/rss+xml; charset=utf-8"
+	case Atom:
+		return "application/atom+xml; charset=utf-8"
+	default:
+		return "application/feed+json; charset=utf-8"
+	}
+}
+
+// Feed is a format-independent description of a feed. All links must be
+// absolute.
+type Feed struct {
+	Title       string
+	Description string
+	Link        string
+	FeedURL     string
+	Updated     time.Time
+	Items       []Item
+}
+
+type Item struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string
+	Content   string
+	Author    string
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+}
+
+// Encode renders f in the given format.
+func Encode(format Format, f Feed) ([]byte, error) {
+	switch format {
+	case RSS:
+		return encodeXML(toRSS(f))
+	case Atom:
+		return encodeXML(toAtom(f))
+	case JSON:
+		return json.MarshalIndent(toJSON(f), "", "  ")
+	}
+	return nil, fmt.Errorf("unknown feed format %q", format)
+}
+
+func encodeXML(v any) ([]byte, error) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// Extension elements (atom:link, content:encoded, dc:creator) are written
+// with a default namespace declaration on the element itself, which is
+// equivalent to a prefixed name for any namespace-aware reader.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Self          atomLink  `xml:"http://www.w3.org/2005/Atom link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func toRSS(f Feed) rssFeed {
+	channel := rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   f.Description,
+		LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		Self:          atomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+	}
+	for _, it := range f.Items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       it.Title,
+			Link:        it.Link,
+			GUID:        rssGUID{IsPermaLink: it.ID == it.Link, Value: it.ID},
+			Description: it.Summary,
+			Content:     it.Content,
+			Creator:     it.Author,
+			Categories:  it.Tags,
+			PubDate:     it.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return rssFeed{Version: "2.0", Channel: channel}
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func toAtom(f Feed) atomFeed {
+	feed := atomFeed{
+		Title:    f.Title,
+		Subtitle: f.Description,
+		ID:       f.FeedURL,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate"},
+		},
+	}
+	for _, it := range f.Items {
+		entry := atomEntry{
+			Title:     it.Title,
+			ID:        it.ID,
+			Link:      atomLink{Href: it.Link, Rel: "alternate"},
+			Published: it.Published.UTC().Format(time.RFC3339),
+			Updated:   it.Updated.UTC().Format(time.RFC3339),
+			Author:    atomPerson{Name: it.Author},
+			Summary:   it.Summary,
+			Content:   atomContent{Type: "html", Value: it.Content},
+		}
+		for _, tag := range it.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	HomePageURL string     `json:"home_page_url"`
+	FeedURL     string     `json:"feed_url"`
+	Description string     `json:"description,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url"`
+	Title         string       `json:"title"`
+	ContentHTML   string       `json:"content_html"`
+	Summary       string       `json:"summary,omitempty"`
+	DatePublished string       `json:"date_published"`
+	DateModified  string       `json:"date_modified"`
+	Authors       []jsonAuthor `json:"authors,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+func toJSON(f Feed) jsonFeed {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       []jsonItem{},
+	}
+	for _, it := range f.Items {
+		item := jsonItem{
+			ID:            it.ID,
+			URL:           it.Link,
+			Title:         it.Title,
+			ContentHTML:   it.Content,
+			Summary:       it.Summary,
+			DatePublished: it.Published.UTC().Format(time.RFC3339),
+			DateModified:  it.Updated.UTC().Format(time.RFC3339),
+			Tags:          it.Tags,
+		}
+		if it.Author != "" {
+			item.Authors = []jsonAuthor{{Name: it.Author}}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
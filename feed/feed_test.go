@@ -0,0 +1,113 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var sample = Feed{
+	Title:       "bubble",
+	Description: "Um blog",
+	Link:        "https://blog.example/entries",
+	FeedURL:     "https://blog.example/feed.xml",
+	Updated:     time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC),
+	Items: []Item{{
+		ID:        "https://blog.example/entries/slug/ola",
+		Title:     "Olá & adeus",
+		Link:      "https://blog.example/entries/slug/ola",
+		Summary:   "Resumo",
+		Content:   "<p>Olá <b>mundo</b></p>",
+		Author:    "juan",
+		Tags:      []string{"go", "sql"},
+		Published: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Updated:   time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC),
+	}},
+}
+
+func TestEncodeRSS(t *testing.T) {
+	out, err := Encode(RSS, sample)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var doc rssFeed
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Expected valid XML, got %v\n%s", err, out)
+	}
+
+	if doc.Version != "2.0" || len(doc.Channel.Items) != 1 {
+		t.Fatalf("Unexpected document %+v", doc)
+	}
+
+	item := doc.Channel.Items[0]
+	if item.Title != "Olá & adeus" || item.Content != "<p>Olá <b>mundo</b></p>" || !item.GUID.IsPermaLink {
+		t.Errorf("Unexpected item %+v", item)
+	}
+
+	if item.PubDate != "Sat, 01 Mar 2025 12:00:00 +0000" {
+		t.Errorf("Expected RFC 1123 date, got %q", item.PubDate)
+	}
+
+	if !strings.Contains(string(out), `<link xmlns="http://www.w3.org/2005/Atom" href="https://blog.example/feed.xml" rel="self"`) {
+		t.Errorf("Expected self link, got\n%s", out)
+	}
+}
+
+func TestEncodeAtom(t *testing.T) {
+	out, err := Encode(Atom, sample)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(string(out), `<feed xmlns="http://www.w3.org/2005/Atom">`) {
+		t.Errorf("Expected Atom namespace, got\n%s", out)
+	}
+
+	var doc atomFeed
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Expected valid XML, got %v", err)
+	}
+
+	if doc.Updated != "2025-03-02T10:00:00Z" || len(doc.Entries) != 1 {
+		t.Fatalf("Unexpected document %+v", doc)
+	}
+
+	entry := doc.Entries[0]
+	if entry.Content.Type != "html" || entry.Author.Name != "juan" || len(entry.Categories) != 2 {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	out, err := Encode(JSON, sample)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var doc jsonFeed
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+
+	if doc.Version != "https://jsonfeed.org/version/1.1" || doc.FeedURL != sample.FeedURL || len(doc.Items) != 1 {
+		t.Fatalf("Unexpected document %+v", doc)
+	}
+
+	if doc.Items[0].DateModified != "2025-03-02T10:00:00Z" {
+		t.Errorf("Expected modified date, got %q", doc.Items[0].DateModified)
+	}
+}
+
+func TestEncodeEmptyJSON(t *testing.T) {
+	out, err := Encode(JSON, Feed{Title: "bubble"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(string(out), `"items": []`) {
+		t.Errorf("Expected an empty items array, got\n%s", out)
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/juanplagos/bubble/auth"
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+type AuthHandler struct {
+	useCase usecase.AuthUseCase
+	logger  *slog.Logger
+}
+
+func NewAuthHandler(useCase usecase.AuthUseCase, logger *slog.Logger) *AuthHandler {
+	return &AuthHandler{
+		useCase: useCase,
+		logger:  logging.OrDiscard(logger),
+	}
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid request body")
+		return
+	}
+
+	token, err := h.useCase.Login(r.Context(), req.Username, req.Password)
+	if err != nil {
+		WriteDomainError(w, err, "failed to log in")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, token, "logged in successfully")
+}
+
+func (h *AuthHandler) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return h.authenticate(next, true)
+}
+
+// OptionalAuth identifies the caller when a token is sent but lets
+// anonymous requests through, for routes whose output depends on who asks.
+func (h *AuthHandler) OptionalAuth(next http.HandlerFunc) http.HandlerFunc {
+	return h.authenticate(next, false)
+}
+
+func (h *AuthHandler) authenticate(next http.HandlerFunc, required bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" && !required {
+			next(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			WriteError(w, http.StatusUnauthorized, nil, "authentication required")
+			return
+		}
+
+		author, err := h.useCase.Authenticate(r.Context(), token)
+		if errors.Is(err, auth.ErrInvalidToken) {
+			// Expired tokens are routine; forged or stale ones are not.
+			h.logger.WarnContext(r.Context(), "token rejeitado", "error", err)
+		}
+		if err != nil {
+			if StatusFromError(err) == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			}
+			WriteDomainError(w, err, "invalid or expired token")
+			return
+		}
+
+		if rec := recorderFrom(w); rec != nil {
+			rec.author = author.Username
+		}
+		next(w, r.WithContext(auth.WithAuthor(r.Context(), author)))
+	}
+}
+
+func callerFromRequest(w http.ResponseWriter, r *http.Request) (model.Author, bool) {
+	author, ok := auth.AuthorFromContext(r.Context())
+	if !ok {
+		WriteError(w, http.StatusUnauthorized, nil, "authentication required")
+	}
+	return author, ok
+}
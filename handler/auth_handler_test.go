@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/juanplagos/bubble/auth"
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+type mockAuthUseCase struct {
+	token    auth.Token
+	author   model.Author
+	loginErr error
+	authErr  error
+}
+
+func (m *mockAuthUseCase) Login(ctx context.Context, username, password string) (auth.Token, error) {
+	return m.token, m.loginErr
+}
+
+func (m *mockAuthUseCase) Authenticate(ctx context.Context, token string) (model.Author, error) {
+	return m.author, m.authErr
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUC := &mockAuthUseCase{token: auth.Token{Token: "abc", ExpiresAt: time.Now().Add(time.Hour)}}
+		handler := NewAuthHandler(mockUC, nil)
+
+		body, _ := json.Marshal(loginRequest{Username: "user1", Password: "pass1"})
+		req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		handler.Login(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		mockUC := &mockAuthUseCase{loginErr: usecase.ErrInvalidCredentials}
+		handler := NewAuthHandler(mockUC, nil)
+
+		body, _ := json.Marshal(loginRequest{Username: "user1", Password: "wrong"})
+		req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		handler.Login(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		handler := NewAuthHandler(&mockAuthUseCase{}, nil)
+
+		req := httptest.NewRequest("POST", "/auth/login", bytes.NewBufferString("invalid json"))
+		w := httptest.NewRecorder()
+
+		handler.Login(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
+
+func TestAuthHandler_RequireAuth(t *testing.T) {
+	t.Run("valid token", func(t *testing.T) {
+		mockUC := &mockAuthUseCase{author: model.Author{Username: "user1"}}
+		handler := NewAuthHandler(mockUC, nil)
+
+		var got model.Author
+		next := handler.RequireAuth(func(w http.ResponseWriter, r *http.Request) {
+			got, _ = auth.AuthorFromContext(r.Context())
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		req := httptest.NewRequest("POST", "/entries", nil)
+		req.Header.Set("Authorization", "Bearer abc")
+		w := httptest.NewRecorder()
+
+		next(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+		}
+
+		if got.Username != "user1" {
+			t.Errorf("Expected author 'user1' in context, got %q", got.Username)
+		}
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		handler := NewAuthHandler(&mockAuthUseCase{}, nil)
+		next := handler.RequireAuth(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Expected next handler not to be called")
+		})
+
+		req := httptest.NewRequest("POST", "/entries", nil)
+		w := httptest.NewRecorder()
+
+		next(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		handler := NewAuthHandler(&mockAuthUseCase{authErr: auth.ErrExpiredToken}, nil)
+		next := handler.RequireAuth(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Expected next handler not to be called")
+		})
+
+		req := httptest.NewRequest("POST", "/entries", nil)
+		req.Header.Set("Authorization", "Bearer abc")
+		w := httptest.NewRecorder()
+
+		next(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+}
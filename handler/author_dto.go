@@ -0,0 +1,41 @@
+package handler
+
+import "github.com/juanplagos/bubble/model"
+
+type authorRequest struct {
+	Username string     `json:"username"`
+	Email    string     `json:"email"`
+	Password string     `json:"password"`
+	Role     model.Role `json:"role"`
+}
+
+func (req authorRequest) toModel() model.Author {
+	return model.Author{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
+		Role:     req.Role,
+	}
+}
+
+type AuthorResponse struct {
+	Username string     `json:"username"`
+	Email    string     `json:"email"`
+	Role     model.Role `json:"role"`
+}
+
+func NewAuthorResponse(author model.Author) AuthorResponse {
+	return AuthorResponse{
+		Username: author.Username,
+		Email:    author.Email,
+		Role:     author.Role,
+	}
+}
+
+func NewAuthorResponses(authors []model.Author) []AuthorResponse {
+	responses := make([]AuthorResponse, 0, len(authors))
+	for _, a := range authors {
+		responses = append(responses, NewAuthorResponse(a))
+	}
+	return responses
+}
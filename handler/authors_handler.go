@@ -2,30 +2,33 @@ package handler
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"strings"
 
-	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/logging"
 	"github.com/juanplagos/bubble/usecase"
 )
 
 type AuthorHandler struct {
 	useCase usecase.AuthorUseCase
+	logger  *slog.Logger
 }
 
-func NewAuthorHandler(useCase usecase.AuthorUseCase) *AuthorHandler {
+func NewAuthorHandler(useCase usecase.AuthorUseCase, logger *slog.Logger) *AuthorHandler {
 	return &AuthorHandler{
 		useCase: useCase,
+		logger:  logging.OrDiscard(logger),
 	}
 }
 
 func (h *AuthorHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	authors, err := h.useCase.GetAllAuthors()
+	authors, err := h.useCase.GetAllAuthors(r.Context())
 	if err != nil {
-		WriteError(w, http.StatusInternalServerError, err, "não foi possível obter os autores")
+		writeDomainError(h.logger, w, r, err, "não foi possível obter os autores")
 		return
 	}
-	WriteSuccess(w, http.StatusOK, authors, "authors retrieved successfully")
+	WriteSuccess(w, http.StatusOK, NewAuthorResponses(authors), "authors retrieved successfully")
 }
 
 func (h *AuthorHandler) GetByUsername(w http.ResponseWriter, r *http.Request) {
@@ -35,12 +38,12 @@ func (h *AuthorHandler) GetByUsername(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	author, err := h.useCase.GetAuthorByUsername(username)
+	author, err := h.useCase.GetAuthorByUsername(r.Context(), username)
 	if err != nil {
-		WriteError(w, http.StatusNotFound, err, "author not found")
+		writeDomainError(h.logger, w, r, err, "failed to retrieve author")
 		return
 	}
-	WriteSuccess(w, http.StatusOK, author, "author retrieved successfully")
+	WriteSuccess(w, http.StatusOK, NewAuthorResponse(author), "author retrieved successfully")
 }
 
 func (h *AuthorHandler) GetByEmail(w http.ResponseWriter, r *http.Request) {
@@ -50,57 +53,74 @@ func (h *AuthorHandler) GetByEmail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	author, err := h.useCase.GetAuthorByEmail(email)
+	author, err := h.useCase.GetAuthorByEmail(r.Context(), email)
 	if err != nil {
-		WriteError(w, http.StatusNotFound, err, "author not found")
+		writeDomainError(h.logger, w, r, err, "failed to retrieve author")
 		return
 	}
-	WriteSuccess(w, http.StatusOK, author, "author retrieved successfully")
+	WriteSuccess(w, http.StatusOK, NewAuthorResponse(author), "author retrieved successfully")
 }
 
 func (h *AuthorHandler) Create(w http.ResponseWriter, r *http.Request) {
-	var author model.Author
-	if err := json.NewDecoder(r.Body).Decode(&author); err != nil {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req authorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		WriteError(w, http.StatusBadRequest, err, "invalid request body")
 		return
 	}
 
-	if err := h.useCase.CreateAuthor(&author); err != nil {
-		WriteError(w, http.StatusInternalServerError, err, "failed to create author")
+	author := req.toModel()
+	if err := h.useCase.CreateAuthor(r.Context(), caller, &author); err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to create author")
 		return
 	}
-	WriteSuccess(w, http.StatusCreated, author, "author created successfully")
+	WriteSuccess(w, http.StatusCreated, NewAuthorResponse(author), "author created successfully")
 }
 
 func (h *AuthorHandler) Update(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
 	username := strings.TrimPrefix(r.URL.Path, "/authors/")
 	if username == "" {
 		WriteError(w, http.StatusBadRequest, nil, "username is required")
 		return
 	}
 
-	var author model.Author
-	if err := json.NewDecoder(r.Body).Decode(&author); err != nil {
+	var req authorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		WriteError(w, http.StatusBadRequest, err, "invalid request body")
 		return
 	}
 
-	if err := h.useCase.UpdateAuthor(username, &author); err != nil {
-		WriteError(w, http.StatusInternalServerError, err, "failed to update author")
+	author := req.toModel()
+	if err := h.useCase.UpdateAuthor(r.Context(), caller, username, &author); err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to update author")
 		return
 	}
-	WriteSuccess(w, http.StatusOK, author, "author updated successfully")
+	WriteSuccess(w, http.StatusOK, NewAuthorResponse(author), "author updated successfully")
 }
 
 func (h *AuthorHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
 	username := strings.TrimPrefix(r.URL.Path, "/authors/")
 	if username == "" {
 		WriteError(w, http.StatusBadRequest, nil, "username is required")
 		return
 	}
 
-	if err := h.useCase.DeleteAuthor(username); err != nil {
-		WriteError(w, http.StatusInternalServerError, err, "failed to delete author")
+	if err := h.useCase.DeleteAuthor(r.Context(), caller, username); err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to delete author")
 		return
 	}
 	WriteSuccess(w, http.StatusOK, nil, "author deleted successfully")
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -9,6 +10,8 @@ import (
 	"testing"
 
 	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/usecase"
 )
 
 type mockAuthorUseCase struct {
@@ -20,37 +23,41 @@ type mockAuthorUseCase struct {
 	deleteErr error
 }
 
-func (m *mockAuthorUseCase) GetAllAuthors() ([]model.Author, error) {
+func (m *mockAuthorUseCase) GetAllAuthors(ctx context.Context) ([]model.Author, error) {
 	return m.authors, m.err
 }
 
-func (m *mockAuthorUseCase) GetAuthorByUsername(username string) (model.Author, error) {
+func (m *mockAuthorUseCase) GetAuthorByUsername(ctx context.Context, username string) (model.Author, error) {
 	return m.author, m.err
 }
 
-func (m *mockAuthorUseCase) GetAuthorByEmail(email string) (model.Author, error) {
+func (m *mockAuthorUseCase) GetAuthorByEmail(ctx context.Context, email string) (model.Author, error) {
 	return m.author, m.err
 }
 
-func (m *mockAuthorUseCase) CreateAuthor(author *model.Author) error {
+func (m *mockAuthorUseCase) CreateAuthor(ctx context.Context, caller model.Author, author *model.Author) error {
 	return m.createErr
 }
 
-func (m *mockAuthorUseCase) UpdateAuthor(username string, author *model.Author) error {
+func (m *mockAuthorUseCase) UpdateAuthor(ctx context.Context, caller model.Author, username string, author *model.Author) error {
 	return m.updateErr
 }
 
-func (m *mockAuthorUseCase) DeleteAuthor(username string) error {
+func (m *mockAuthorUseCase) DeleteAuthor(ctx context.Context, caller model.Author, username string) error {
 	return m.deleteErr
 }
 
+func (m *mockAuthorUseCase) VerifyCredentials(ctx context.Context, username, password string) (model.Author, error) {
+	return m.author, m.err
+}
+
 func TestAuthorHandler_GetAll(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		authors := []model.Author{
 			{Username: "user1", Email: "user1@test.com", Password: "pass1"},
 		}
 		mockUC := &mockAuthorUseCase{authors: authors}
-		handler := NewAuthorHandler(mockUC)
+		handler := NewAuthorHandler(mockUC, nil)
 
 		req := httptest.NewRequest("GET", "/authors", nil)
 		w := httptest.NewRecorder()
@@ -69,11 +76,15 @@ func TestAuthorHandler_GetAll(t *testing.T) {
 		if !response.Success {
 			t.Error("Expected success to be true")
 		}
+
+		if bytes.Contains(w.Body.Bytes(), []byte("pass1")) {
+			t.Error("Expected password to be omitted from the response")
+		}
 	})
 
 	t.Run("error", func(t *testing.T) {
 		mockUC := &mockAuthorUseCase{err: errors.New("database error")}
-		handler := NewAuthorHandler(mockUC)
+		handler := NewAuthorHandler(mockUC, nil)
 
 		req := httptest.NewRequest("GET", "/authors", nil)
 		w := httptest.NewRecorder()
@@ -90,7 +101,7 @@ func TestAuthorHandler_GetByUsername(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		author := model.Author{Username: "user1", Email: "user1@test.com", Password: "pass1"}
 		mockUC := &mockAuthorUseCase{author: author}
-		handler := NewAuthorHandler(mockUC)
+		handler := NewAuthorHandler(mockUC, nil)
 
 		req := httptest.NewRequest("GET", "/authors/user1", nil)
 		w := httptest.NewRecorder()
@@ -103,7 +114,7 @@ func TestAuthorHandler_GetByUsername(t *testing.T) {
 	})
 
 	t.Run("empty username", func(t *testing.T) {
-		handler := NewAuthorHandler(&mockAuthorUseCase{})
+		handler := NewAuthorHandler(&mockAuthorUseCase{}, nil)
 
 		req := httptest.NewRequest("GET", "/authors/", nil)
 		w := httptest.NewRecorder()
@@ -116,8 +127,8 @@ func TestAuthorHandler_GetByUsername(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		mockUC := &mockAuthorUseCase{err: errors.New("not found")}
-		handler := NewAuthorHandler(mockUC)
+		mockUC := &mockAuthorUseCase{err: repository.ErrNotFound}
+		handler := NewAuthorHandler(mockUC, nil)
 
 		req := httptest.NewRequest("GET", "/authors/nonexistent", nil)
 		w := httptest.NewRecorder()
@@ -134,7 +145,7 @@ func TestAuthorHandler_GetByEmail(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		author := model.Author{Username: "user1", Email: "user1@test.com", Password: "pass1"}
 		mockUC := &mockAuthorUseCase{author: author}
-		handler := NewAuthorHandler(mockUC)
+		handler := NewAuthorHandler(mockUC, nil)
 
 		req := httptest.NewRequest("GET", "/authors/email/user1@test.com", nil)
 		w := httptest.NewRecorder()
@@ -147,7 +158,7 @@ func TestAuthorHandler_GetByEmail(t *testing.T) {
 	})
 
 	t.Run("empty email", func(t *testing.T) {
-		handler := NewAuthorHandler(&mockAuthorUseCase{})
+		handler := NewAuthorHandler(&mockAuthorUseCase{}, nil)
 
 		req := httptest.NewRequest("GET", "/authors/email/", nil)
 		w := httptest.NewRecorder()
@@ -163,11 +174,11 @@ func TestAuthorHandler_GetByEmail(t *testing.T) {
 func TestAuthorHandler_Create(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockUC := &mockAuthorUseCase{}
-		handler := NewAuthorHandler(mockUC)
+		handler := NewAuthorHandler(mockUC, nil)
 
-		author := model.Author{Username: "user1", Email: "user1@test.com", Password: "pass1"}
+		author := authorRequest{Username: "user1", Email: "user1@test.com", Password: "pass1"}
 		body, _ := json.Marshal(author)
-		req := httptest.NewRequest("POST", "/authors", bytes.NewBuffer(body))
+		req := withCaller(httptest.NewRequest("POST", "/authors", bytes.NewBuffer(body)))
 		w := httptest.NewRecorder()
 
 		handler.Create(w, req)
@@ -175,12 +186,47 @@ func TestAuthorHandler_Create(t *testing.T) {
 		if w.Code != http.StatusCreated {
 			t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
 		}
+
+		if bytes.Contains(w.Body.Bytes(), []byte("password")) {
+			t.Error("Expected password to be omitted from the response")
+		}
 	})
 
 	t.Run("invalid body", func(t *testing.T) {
-		handler := NewAuthorHandler(&mockAuthorUseCase{})
+		handler := NewAuthorHandler(&mockAuthorUseCase{}, nil)
+
+		req := withCaller(httptest.NewRequest("POST", "/authors", bytes.NewBufferString("invalid json")))
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		handler := NewAuthorHandler(&mockAuthorUseCase{createErr: usecase.ErrForbidden}, nil)
 
-		req := httptest.NewRequest("POST", "/authors", bytes.NewBufferString("invalid json"))
+		author := authorRequest{Username: "user1", Email: "user1@test.com", Password: "pass1"}
+		body, _ := json.Marshal(author)
+		req := withCaller(httptest.NewRequest("POST", "/authors", bytes.NewBuffer(body)))
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		validationErr := &usecase.ValidationError{Fields: []usecase.FieldError{{Field: "role", Message: "must be one of admin, editor, writer or reader"}}}
+		handler := NewAuthorHandler(&mockAuthorUseCase{createErr: validationErr}, nil)
+
+		author := authorRequest{Username: "user1", Email: "user1@test.com", Password: "pass1", Role: "overlord"}
+		body, _ := json.Marshal(author)
+		req := withCaller(httptest.NewRequest("POST", "/authors", bytes.NewBuffer(body)))
 		w := httptest.NewRecorder()
 
 		handler.Create(w, req)
@@ -188,15 +234,24 @@ func TestAuthorHandler_Create(t *testing.T) {
 		if w.Code != http.StatusBadRequest {
 			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
 		}
+
+		var response Response
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+
+		if len(response.Details) != 1 || response.Details[0].Field != "role" {
+			t.Errorf("Expected role error in details, got %v", response.Details)
+		}
 	})
 
 	t.Run("create error", func(t *testing.T) {
 		mockUC := &mockAuthorUseCase{createErr: errors.New("database error")}
-		handler := NewAuthorHandler(mockUC)
+		handler := NewAuthorHandler(mockUC, nil)
 
-		author := model.Author{Username: "user1", Email: "user1@test.com", Password: "pass1"}
+		author := authorRequest{Username: "user1", Email: "user1@test.com", Password: "pass1"}
 		body, _ := json.Marshal(author)
-		req := httptest.NewRequest("POST", "/authors", bytes.NewBuffer(body))
+		req := withCaller(httptest.NewRequest("POST", "/authors", bytes.NewBuffer(body)))
 		w := httptest.NewRecorder()
 
 		handler.Create(w, req)
@@ -210,11 +265,11 @@ func TestAuthorHandler_Create(t *testing.T) {
 func TestAuthorHandler_Update(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockUC := &mockAuthorUseCase{}
-		handler := NewAuthorHandler(mockUC)
+		handler := NewAuthorHandler(mockUC, nil)
 
-		author := model.Author{Username: "user1", Email: "updated@test.com", Password: "newpass"}
+		author := authorRequest{Username: "user1", Email: "updated@test.com", Password: "newpass"}
 		body, _ := json.Marshal(author)
-		req := httptest.NewRequest("PUT", "/authors/user1", bytes.NewBuffer(body))
+		req := withCaller(httptest.NewRequest("PUT", "/authors/user1", bytes.NewBuffer(body)))
 		w := httptest.NewRecorder()
 
 		handler.Update(w, req)
@@ -225,9 +280,9 @@ func TestAuthorHandler_Update(t *testing.T) {
 	})
 
 	t.Run("empty username", func(t *testing.T) {
-		handler := NewAuthorHandler(&mockAuthorUseCase{})
+		handler := NewAuthorHandler(&mockAuthorUseCase{}, nil)
 
-		req := httptest.NewRequest("PUT", "/authors/", nil)
+		req := withCaller(httptest.NewRequest("PUT", "/authors/", nil))
 		w := httptest.NewRecorder()
 
 		handler.Update(w, req)
@@ -241,9 +296,9 @@ func TestAuthorHandler_Update(t *testing.T) {
 func TestAuthorHandler_Delete(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockUC := &mockAuthorUseCase{}
-		handler := NewAuthorHandler(mockUC)
+		handler := NewAuthorHandler(mockUC, nil)
 
-		req := httptest.NewRequest("DELETE", "/authors/user1", nil)
+		req := withCaller(httptest.NewRequest("DELETE", "/authors/user1", nil))
 		w := httptest.NewRecorder()
 
 		handler.Delete(w, req)
@@ -254,9 +309,9 @@ func TestAuthorHandler_Delete(t *testing.T) {
 	})
 
 	t.Run("empty username", func(t *testing.T) {
-		handler := NewAuthorHandler(&mockAuthorUseCase{})
+		handler := NewAuthorHandler(&mockAuthorUseCase{}, nil)
 
-		req := httptest.NewRequest("DELETE", "/authors/", nil)
+		req := withCaller(httptest.NewRequest("DELETE", "/authors/", nil))
 		w := httptest.NewRecorder()
 
 		handler.Delete(w, req)
@@ -0,0 +1,21 @@
+package handler
+
+import "github.com/juanplagos/bubble/model"
+
+// commentRequest is what readers may send when commenting; the status and
+// entry are decided by the server.
+type commentRequest struct {
+	ParentID    *int   `json:"parent_id"`
+	AuthorName  string `json:"author_name"`
+	AuthorEmail string `json:"author_email"`
+	Body        string `json:"body"`
+}
+
+func (req commentRequest) toModel() model.Comment {
+	return model.Comment{
+		ParentID:    req.ParentID,
+		AuthorName:  req.AuthorName,
+		AuthorEmail: req.AuthorEmail,
+		Body:        req.Body,
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/juanplagos/bubble/auth"
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+type CommentHandler struct {
+	useCase usecase.CommentUseCase
+	logger  *slog.Logger
+}
+
+func NewCommentHandler(useCase usecase.CommentUseCase, logger *slog.Logger) *CommentHandler {
+	return &CommentHandler{
+		useCase: useCase,
+		logger:  logging.OrDiscard(logger),
+	}
+}
+
+func (h *CommentHandler) GetForEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid entry ID")
+		return
+	}
+
+	viewer, _ := auth.AuthorFromContext(r.Context())
+	comments, err := h.useCase.GetEntryComments(r.Context(), viewer, entryID)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to retrieve comments")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, comments, "comments retrieved successfully")
+}
+
+// Create is open to anonymous readers; a signed-in caller comments under
+// their account.
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	entryID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid entry ID")
+		return
+	}
+
+	var req commentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid request body")
+		return
+	}
+
+	caller, _ := auth.AuthorFromContext(r.Context())
+	comment := req.toModel()
+	if err := h.useCase.CreateComment(r.Context(), caller, entryID, &comment); err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to create comment")
+		return
+	}
+
+	if comment.Status != model.CommentApproved {
+		WriteSuccess(w, http.StatusAccepted, comment, "comment awaiting moderation")
+		return
+	}
+	WriteSuccess(w, http.StatusCreated, comment, "comment created successfully")
+}
+
+func (h *CommentHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := parseCommentQuery(r.URL.Query())
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid query parameters")
+		return
+	}
+
+	comments, err := h.useCase.GetModerationQueue(r.Context(), caller, query)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to retrieve comments")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, comments, "comments retrieved successfully")
+}
+
+func (h *CommentHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, h.useCase.ApproveComment, "comment approved successfully")
+}
+
+func (h *CommentHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, h.useCase.RejectComment, "comment rejected successfully")
+}
+
+func (h *CommentHandler) moderate(w http.ResponseWriter, r *http.Request, action func(context.Context, model.Author, int) (model.Comment, error), message string) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid comment ID")
+		return
+	}
+
+	comment, err := action(r.Context(), caller, id)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to moderate comment")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, comment, message)
+}
+
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid comment ID")
+		return
+	}
+
+	if err := h.useCase.DeleteComment(r.Context(), caller, id); err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to delete comment")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, nil, "comment deleted successfully")
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+type mockCommentUseCase struct {
+	comments  []model.Comment
+	comment   model.Comment
+	status    model.CommentStatus
+	caller    model.Author
+	query     model.CommentQuery
+	err       error
+	createErr error
+}
+
+func (m *mockCommentUseCase) GetEntryComments(ctx context.Context, viewer model.Author, entryID int) ([]model.Comment, error) {
+	return m.comments, m.err
+}
+
+func (m *mockCommentUseCase) CreateComment(ctx context.Context, caller model.Author, entryID int, comment *model.Comment) error {
+	m.caller = caller
+	comment.EntryID, comment.Status = entryID, m.status
+	return m.createErr
+}
+
+func (m *mockCommentUseCase) GetModerationQueue(ctx context.Context, caller model.Author, query model.CommentQuery) ([]model.Comment, error) {
+	m.query = query
+	return m.comments, m.err
+}
+
+func (m *mockCommentUseCase) ApproveComment(ctx context.Context, caller model.Author, id int) (model.Comment, error) {
+	return model.Comment{ID: id, Status: model.CommentApproved}, m.err
+}
+
+func (m *mockCommentUseCase) RejectComment(ctx context.Context, caller model.Author, id int) (model.Comment, error) {
+	return model.Comment{ID: id, Status: model.CommentSpam}, m.err
+}
+
+func (m *mockCommentUseCase) DeleteComment(ctx context.Context, caller model.Author, id int) error {
+	return m.err
+}
+
+func TestCommentHandler_GetForEntry(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUC := &mockCommentUseCase{comments: []model.Comment{{ID: 1, Replies: []model.Comment{{ID: 2}}}}}
+		handler := NewCommentHandler(mockUC, nil)
+
+		req := httptest.NewRequest("GET", "/entries/comments/1", nil)
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.GetForEntry(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		var response struct {
+			Data []model.Comment `json:"data"`
+		}
+		json.NewDecoder(w.Body).Decode(&response)
+		if len(response.Data) != 1 || len(response.Data[0].Replies) != 1 {
+			t.Errorf("Expected a thread, got %+v", response.Data)
+		}
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		handler := NewCommentHandler(&mockCommentUseCase{}, nil)
+
+		req := httptest.NewRequest("GET", "/entries/comments/abc", nil)
+		req.SetPathValue("id", "abc")
+		w := httptest.NewRecorder()
+
+		handler.GetForEntry(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("entry not found", func(t *testing.T) {
+		handler := NewCommentHandler(&mockCommentUseCase{err: repository.ErrNotFound}, nil)
+
+		req := httptest.NewRequest("GET", "/entries/comments/9", nil)
+		req.SetPathValue("id", "9")
+		w := httptest.NewRecorder()
+
+		handler.GetForEntry(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
+
+func TestCommentHandler_Create(t *testing.T) {
+	body := []byte(`{"author_name":"Ana","author_email":"ana@test.com","body":"Olá","status":"approved"}`)
+
+	t.Run("pending", func(t *testing.T) {
+		handler := NewCommentHandler(&mockCommentUseCase{status: model.CommentPending}, nil)
+
+		req := httptest.NewRequest("POST", "/entries/comments/1", bytes.NewBuffer(body))
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		if w.Code != http.StatusAccepted {
+			t.Errorf("Expected status %d, got %d", http.StatusAccepted, w.Code)
+		}
+	})
+
+	t.Run("approved", func(t *testing.T) {
+		mockUC := &mockCommentUseCase{status: model.CommentApproved}
+		handler := NewCommentHandler(mockUC, nil)
+
+		req := withCaller(httptest.NewRequest("POST", "/entries/comments/1", bytes.NewBuffer(body)))
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+
+		if mockUC.caller.Username != "author" {
+			t.Errorf("Expected the caller to be passed on, got %+v", mockUC.caller)
+		}
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		handler := NewCommentHandler(&mockCommentUseCase{createErr: &usecase.ValidationError{Fields: []usecase.FieldError{{Field: "body", Message: "is required"}}}}, nil)
+
+		req := httptest.NewRequest("POST", "/entries/comments/1", bytes.NewBuffer(body))
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
+
+func TestCommentHandler_Moderation(t *testing.T) {
+	t.Run("queue", func(t *testing.T) {
+		mockUC := &mockCommentUseCase{comments: []model.Comment{{ID: 1}}}
+		handler := NewCommentHandler(mockUC, nil)
+
+		req := withCaller(httptest.NewRequest("GET", "/comments?status=spam&limit=5", nil))
+		w := httptest.NewRecorder()
+
+		handler.GetQueue(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		if mockUC.query.Status != model.CommentSpam || mockUC.query.Limit != 5 {
+			t.Errorf("Expected parsed query, got %+v", mockUC.query)
+		}
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		handler := NewCommentHandler(&mockCommentUseCase{}, nil)
+
+		req := withCaller(httptest.NewRequest("POST", "/comments/3/approve", nil))
+		req.SetPathValue("id", "3")
+		w := httptest.NewRecorder()
+
+		handler.Approve(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		handler := NewCommentHandler(&mockCommentUseCase{err: usecase.ErrForbidden}, nil)
+
+		req := withCaller(httptest.NewRequest("POST", "/comments/3/reject", nil))
+		req.SetPathValue("id", "3")
+		w := httptest.NewRecorder()
+
+		handler.Reject(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		handler := NewCommentHandler(&mockCommentUseCase{}, nil)
+
+		req := httptest.NewRequest("DELETE", "/comments/3", nil)
+		req.SetPathValue("id", "3")
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+}
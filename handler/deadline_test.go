@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeadline(t *testing.T) {
+	t.Run("sets a deadline", func(t *testing.T) {
+		var deadline time.Time
+		var ok bool
+		h := Deadline(time.Minute, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline, ok = r.Context().Deadline()
+		}))
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/entries", nil))
+
+		if !ok || time.Until(deadline) > time.Minute {
+			t.Errorf("Expected a deadline within a minute, got %v (%v)", deadline, ok)
+		}
+	})
+
+	t.Run("expired deadline is a gateway timeout", func(t *testing.T) {
+		h := Deadline(time.Nanosecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			WriteDomainError(w, r.Context().Err(), "failed to retrieve entries")
+		}))
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/entries", nil))
+
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("Expected status %d, got %d", http.StatusGatewayTimeout, w.Code)
+		}
+	})
+
+	t.Run("zero leaves requests unbounded", func(t *testing.T) {
+		h := Deadline(0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Deadline(); ok {
+				t.Error("Expected no deadline")
+			}
+		}))
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/entries", nil))
+	})
+}
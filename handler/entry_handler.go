@@ -2,31 +2,54 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/juanplagos/bubble/auth"
+	"github.com/juanplagos/bubble/logging"
 	"github.com/juanplagos/bubble/model"
 	"github.com/juanplagos/bubble/usecase"
 )
 
 type EntryHandler struct {
 	useCase usecase.EntryUseCase
+	logger  *slog.Logger
 }
 
-func NewEntryHandler(useCase usecase.EntryUseCase) *EntryHandler {
+func NewEntryHandler(useCase usecase.EntryUseCase, logger *slog.Logger) *EntryHandler {
 	return &EntryHandler{
 		useCase: useCase,
+		logger:  logging.OrDiscard(logger),
 	}
 }
 
 func (h *EntryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	entries, err := h.useCase.GetAllEntries()
+	query, err := parseEntryQuery(r.URL.Query())
 	if err != nil {
-		WriteError(w, http.StatusInternalServerError, err, "não foi possível obter os registros")
+		WriteError(w, http.StatusBadRequest, err, "invalid query parameters")
 		return
 	}
-	WriteSuccess(w, http.StatusOK, entries, "entries retrieved successfully")
+
+	viewer, _ := auth.AuthorFromContext(r.Context())
+	page, err := h.useCase.GetAllEntries(r.Context(), viewer, query)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "não foi possível obter os registros")
+		return
+	}
+
+	entries := page.Entries
+	if entries == nil {
+		entries = []model.Entry{}
+	}
+	WritePaginated(w, http.StatusOK, entries, Pagination{
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+	}, "entries retrieved successfully")
 }
 
 func (h *EntryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
@@ -37,9 +60,10 @@ func (h *EntryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entry, err := h.useCase.GetEntryById(id)
+	viewer, _ := auth.AuthorFromContext(r.Context())
+	entry, err := h.useCase.GetEntryById(r.Context(), viewer, id)
 	if err != nil {
-		WriteError(w, http.StatusNotFound, err, "entry not found")
+		writeDomainError(h.logger, w, r, err, "failed to retrieve entry")
 		return
 	}
 	WriteSuccess(w, http.StatusOK, entry, "entry retrieved successfully")
@@ -52,29 +76,60 @@ func (h *EntryHandler) GetBySlug(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entry, err := h.useCase.GetEntryBySlug(slug)
+	viewer, _ := auth.AuthorFromContext(r.Context())
+	entry, err := h.useCase.GetEntryBySlug(r.Context(), viewer, slug)
+	var moved *usecase.MovedError
+	if errors.As(err, &moved) {
+		http.Redirect(w, r, "/entries/slug/"+moved.Slug, http.StatusMovedPermanently)
+		return
+	}
 	if err != nil {
-		WriteError(w, http.StatusNotFound, err, "entry not found")
+		writeDomainError(h.logger, w, r, err, "failed to retrieve entry")
 		return
 	}
 	WriteSuccess(w, http.StatusOK, entry, "entry retrieved successfully")
 }
 
+func (h *EntryHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query, err := parseSearchQuery(r.URL.Query())
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid query parameters")
+		return
+	}
+
+	results, err := h.useCase.SearchEntries(r.Context(), query)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to search entries")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, results, "entries searched successfully")
+}
+
 func (h *EntryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
 	var entry model.Entry
 	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
 		WriteError(w, http.StatusBadRequest, err, "invalid request body")
 		return
 	}
 
-	if err := h.useCase.CreateEntry(&entry); err != nil {
-		WriteError(w, http.StatusInternalServerError, err, "failed to create entry")
+	if err := h.useCase.CreateEntry(r.Context(), caller, &entry); err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to create entry")
 		return
 	}
 	WriteSuccess(w, http.StatusCreated, entry, "entry created successfully")
 }
 
 func (h *EntryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
 	idStr := strings.TrimPrefix(r.URL.Path, "/entries/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -88,14 +143,19 @@ func (h *EntryHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.useCase.UpdateEntry(id, &entry); err != nil {
-		WriteError(w, http.StatusInternalServerError, err, "failed to update entry")
+	if err := h.useCase.UpdateEntry(r.Context(), caller, id, &entry); err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to update entry")
 		return
 	}
 	WriteSuccess(w, http.StatusOK, entry, "entry updated successfully")
 }
 
 func (h *EntryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
 	idStr := strings.TrimPrefix(r.URL.Path, "/entries/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -103,9 +163,81 @@ func (h *EntryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.useCase.DeleteEntry(id); err != nil {
-		WriteError(w, http.StatusInternalServerError, err, "failed to delete entry")
+	if err := h.useCase.DeleteEntry(r.Context(), caller, id); err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to delete entry")
 		return
 	}
 	WriteSuccess(w, http.StatusOK, nil, "entry deleted successfully")
 }
+
+func (h *EntryHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid entry ID")
+		return
+	}
+
+	revisions, err := h.useCase.GetRevisions(r.Context(), caller, id)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to retrieve revisions")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, revisions, "revisions retrieved successfully")
+}
+
+func (h *EntryHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid entry ID")
+		return
+	}
+
+	from, to, err := parseRevisionRange(r.URL.Query())
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid query parameters")
+		return
+	}
+
+	diff, err := h.useCase.DiffRevisions(r.Context(), caller, id, from, to)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to compare revisions")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, diff, "revisions compared successfully")
+}
+
+func (h *EntryHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid entry ID")
+		return
+	}
+
+	number, err := strconv.Atoi(r.PathValue("revision"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid revision number")
+		return
+	}
+
+	entry, err := h.useCase.RestoreRevision(r.Context(), caller, id, number)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to restore revision")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, entry, "revision restored successfully")
+}
@@ -2,56 +2,93 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/juanplagos/bubble/auth"
 	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/usecase"
 )
 
 type mockEntryUseCase struct {
-	entries   []model.Entry
-	entry     model.Entry
-	err       error
-	createErr error
-	updateErr error
-	deleteErr error
+	results    []model.SearchResult
+	entries    []model.Entry
+	nextCursor string
+	query      model.EntryQuery
+	entry      model.Entry
+	revisions  []model.Revision
+	diff       model.RevisionDiff
+	err        error
+	createErr  error
+	updateErr  error
+	deleteErr  error
 }
 
-func (m *mockEntryUseCase) GetAllEntries() ([]model.Entry, error) {
-	return m.entries, m.err
+func (m *mockEntryUseCase) GetAllEntries(ctx context.Context, viewer model.Author, query model.EntryQuery) (model.EntryPage, error) {
+	m.query = query
+	return model.EntryPage{Entries: m.entries, Total: len(m.entries), Limit: 20, NextCursor: m.nextCursor}, m.err
 }
 
-func (m *mockEntryUseCase) GetEntryById(id int) (model.Entry, error) {
+func (m *mockEntryUseCase) GetEntryById(ctx context.Context, viewer model.Author, id int) (model.Entry, error) {
 	return m.entry, m.err
 }
 
-func (m *mockEntryUseCase) GetEntryBySlug(slug string) (model.Entry, error) {
+func (m *mockEntryUseCase) GetEntryBySlug(ctx context.Context, viewer model.Author, slug string) (model.Entry, error) {
 	return m.entry, m.err
 }
 
-func (m *mockEntryUseCase) CreateEntry(entry *model.Entry) error {
+func (m *mockEntryUseCase) SearchEntries(ctx context.Context, query model.SearchQuery) ([]model.SearchResult, error) {
+	return m.results, m.err
+}
+
+func (m *mockEntryUseCase) CreateEntry(ctx context.Context, caller model.Author, entry *model.Entry) error {
 	return m.createErr
 }
 
-func (m *mockEntryUseCase) UpdateEntry(id int, entry *model.Entry) error {
+func (m *mockEntryUseCase) UpdateEntry(ctx context.Context, caller model.Author, id int, entry *model.Entry) error {
 	return m.updateErr
 }
 
-func (m *mockEntryUseCase) DeleteEntry(id int) error {
+func (m *mockEntryUseCase) DeleteEntry(ctx context.Context, caller model.Author, id int) error {
 	return m.deleteErr
 }
 
+func (m *mockEntryUseCase) PublishScheduledEntries(ctx context.Context) ([]model.Entry, error) {
+	return m.entries, m.err
+}
+
+func (m *mockEntryUseCase) GetRevisions(ctx context.Context, caller model.Author, id int) ([]model.Revision, error) {
+	return m.revisions, m.err
+}
+
+func (m *mockEntryUseCase) DiffRevisions(ctx context.Context, caller model.Author, id, from, to int) (model.RevisionDiff, error) {
+	m.diff.From, m.diff.To = from, to
+	return m.diff, m.err
+}
+
+func (m *mockEntryUseCase) RestoreRevision(ctx context.Context, caller model.Author, id, number int) (model.Entry, error) {
+	return m.entry, m.updateErr
+}
+
+func withCaller(req *http.Request) *http.Request {
+	caller := model.Author{Username: "author", Role: model.RoleWriter}
+	return req.WithContext(auth.WithAuthor(req.Context(), caller))
+}
+
 func TestEntryHandler_GetAll(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		entries := []model.Entry{
 			{ID: 1, Title: "Test", Slug: "test", Body: "Body", Author: "author", CreatedAt: time.Now()},
 		}
 		mockUC := &mockEntryUseCase{entries: entries}
-		handler := NewEntryHandler(mockUC)
+		handler := NewEntryHandler(mockUC, nil)
 
 		req := httptest.NewRequest("GET", "/entries", nil)
 		w := httptest.NewRecorder()
@@ -70,11 +107,96 @@ func TestEntryHandler_GetAll(t *testing.T) {
 		if !response.Success {
 			t.Error("Expected success to be true")
 		}
+
+		if response.Pagination == nil || response.Pagination.Total != 1 {
+			t.Errorf("Expected pagination with total 1, got %+v", response.Pagination)
+		}
+	})
+
+	t.Run("query parameters", func(t *testing.T) {
+		mockUC := &mockEntryUseCase{nextCursor: "abc"}
+		handler := NewEntryHandler(mockUC, nil)
+
+		req := httptest.NewRequest("GET", "/entries?limit=5&sort=title&order=asc&author=juan&status=draft&from=2025-01-01&to=2025-02-01T00:00:00Z", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		q := mockUC.query
+		if q.Limit != 5 || q.Sort != model.SortByTitle || q.Order != model.Ascending || q.Author != "juan" || q.Status != model.StatusDraft {
+			t.Errorf("Unexpected query %+v", q)
+		}
+
+		if q.From == nil || !q.From.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected from 2025-01-01, got %v", q.From)
+		}
+
+		if q.To == nil || !q.To.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected to 2025-02-01, got %v", q.To)
+		}
+
+		var response Response
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+
+		if response.Pagination == nil || response.Pagination.NextCursor != "abc" {
+			t.Errorf("Expected next cursor 'abc', got %+v", response.Pagination)
+		}
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		mockUC := &mockEntryUseCase{}
+		handler := NewEntryHandler(mockUC, nil)
+
+		req := httptest.NewRequest("GET", "/entries?tag=go,sql&tag=%20web%20&tag_match=any", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		q := mockUC.query
+		if len(q.Tags) != 3 || q.Tags[0] != "go" || q.Tags[1] != "sql" || q.Tags[2] != "web" {
+			t.Errorf("Expected tags [go sql web], got %v", q.Tags)
+		}
+
+		if q.TagMatch != model.MatchAnyTag {
+			t.Errorf("Expected tag match %q, got %q", model.MatchAnyTag, q.TagMatch)
+		}
+	})
+
+	t.Run("invalid query parameters", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{}, nil)
+
+		req := httptest.NewRequest("GET", "/entries?limit=ten&from=yesterday", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+
+		var response Response
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+
+		if len(response.Details) != 2 {
+			t.Errorf("Expected 2 field errors, got %v", response.Details)
+		}
 	})
 
 	t.Run("error", func(t *testing.T) {
 		mockUC := &mockEntryUseCase{err: errors.New("database error")}
-		handler := NewEntryHandler(mockUC)
+		handler := NewEntryHandler(mockUC, nil)
 
 		req := httptest.NewRequest("GET", "/entries", nil)
 		w := httptest.NewRecorder()
@@ -91,7 +213,7 @@ func TestEntryHandler_GetByID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		entry := model.Entry{ID: 1, Title: "Test", Slug: "test", Body: "Body", Author: "author", CreatedAt: time.Now()}
 		mockUC := &mockEntryUseCase{entry: entry}
-		handler := NewEntryHandler(mockUC)
+		handler := NewEntryHandler(mockUC, nil)
 
 		req := httptest.NewRequest("GET", "/entries/1", nil)
 		w := httptest.NewRecorder()
@@ -104,7 +226,7 @@ func TestEntryHandler_GetByID(t *testing.T) {
 	})
 
 	t.Run("invalid id", func(t *testing.T) {
-		handler := NewEntryHandler(&mockEntryUseCase{})
+		handler := NewEntryHandler(&mockEntryUseCase{}, nil)
 
 		req := httptest.NewRequest("GET", "/entries/abc", nil)
 		w := httptest.NewRecorder()
@@ -117,8 +239,8 @@ func TestEntryHandler_GetByID(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		mockUC := &mockEntryUseCase{err: errors.New("not found")}
-		handler := NewEntryHandler(mockUC)
+		mockUC := &mockEntryUseCase{err: repository.ErrNotFound}
+		handler := NewEntryHandler(mockUC, nil)
 
 		req := httptest.NewRequest("GET", "/entries/999", nil)
 		w := httptest.NewRecorder()
@@ -135,7 +257,7 @@ func TestEntryHandler_GetBySlug(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		entry := model.Entry{ID: 1, Title: "Test", Slug: "test", Body: "Body", Author: "author", CreatedAt: time.Now()}
 		mockUC := &mockEntryUseCase{entry: entry}
-		handler := NewEntryHandler(mockUC)
+		handler := NewEntryHandler(mockUC, nil)
 
 		req := httptest.NewRequest("GET", "/entries/slug/test", nil)
 		w := httptest.NewRecorder()
@@ -148,7 +270,7 @@ func TestEntryHandler_GetBySlug(t *testing.T) {
 	})
 
 	t.Run("empty slug", func(t *testing.T) {
-		handler := NewEntryHandler(&mockEntryUseCase{})
+		handler := NewEntryHandler(&mockEntryUseCase{}, nil)
 
 		req := httptest.NewRequest("GET", "/entries/slug/", nil)
 		w := httptest.NewRecorder()
@@ -159,16 +281,33 @@ func TestEntryHandler_GetBySlug(t *testing.T) {
 			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
 		}
 	})
+
+	t.Run("renamed", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{err: &usecase.MovedError{Slug: "new"}}, nil)
+
+		req := httptest.NewRequest("GET", "/entries/slug/old", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetBySlug(w, req)
+
+		if w.Code != http.StatusMovedPermanently {
+			t.Errorf("Expected status %d, got %d", http.StatusMovedPermanently, w.Code)
+		}
+
+		if location := w.Header().Get("Location"); location != "/entries/slug/new" {
+			t.Errorf("Expected Location %q, got %q", "/entries/slug/new", location)
+		}
+	})
 }
 
 func TestEntryHandler_Create(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockUC := &mockEntryUseCase{}
-		handler := NewEntryHandler(mockUC)
+		handler := NewEntryHandler(mockUC, nil)
 
 		entry := model.Entry{Title: "Test", Slug: "test", Body: "Body", Author: "author"}
 		body, _ := json.Marshal(entry)
-		req := httptest.NewRequest("POST", "/entries", bytes.NewBuffer(body))
+		req := withCaller(httptest.NewRequest("POST", "/entries", bytes.NewBuffer(body)))
 		w := httptest.NewRecorder()
 
 		handler.Create(w, req)
@@ -179,9 +318,9 @@ func TestEntryHandler_Create(t *testing.T) {
 	})
 
 	t.Run("invalid body", func(t *testing.T) {
-		handler := NewEntryHandler(&mockEntryUseCase{})
+		handler := NewEntryHandler(&mockEntryUseCase{}, nil)
 
-		req := httptest.NewRequest("POST", "/entries", bytes.NewBufferString("invalid json"))
+		req := withCaller(httptest.NewRequest("POST", "/entries", bytes.NewBufferString("invalid json")))
 		w := httptest.NewRecorder()
 
 		handler.Create(w, req)
@@ -191,13 +330,44 @@ func TestEntryHandler_Create(t *testing.T) {
 		}
 	})
 
+	t.Run("unauthenticated", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{}, nil)
+
+		entry := model.Entry{Title: "Test", Slug: "test", Body: "Body"}
+		body, _ := json.Marshal(entry)
+		req := httptest.NewRequest("POST", "/entries", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("duplicate slug", func(t *testing.T) {
+		mockUC := &mockEntryUseCase{createErr: fmt.Errorf("%w: slug already exists", repository.ErrConflict)}
+		handler := NewEntryHandler(mockUC, nil)
+
+		entry := model.Entry{Title: "Test", Slug: "test", Body: "Body"}
+		body, _ := json.Marshal(entry)
+		req := withCaller(httptest.NewRequest("POST", "/entries", bytes.NewBuffer(body)))
+		w := httptest.NewRecorder()
+
+		handler.Create(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status %d, got %d", http.StatusConflict, w.Code)
+		}
+	})
+
 	t.Run("create error", func(t *testing.T) {
 		mockUC := &mockEntryUseCase{createErr: errors.New("database error")}
-		handler := NewEntryHandler(mockUC)
+		handler := NewEntryHandler(mockUC, nil)
 
 		entry := model.Entry{Title: "Test", Slug: "test", Body: "Body", Author: "author"}
 		body, _ := json.Marshal(entry)
-		req := httptest.NewRequest("POST", "/entries", bytes.NewBuffer(body))
+		req := withCaller(httptest.NewRequest("POST", "/entries", bytes.NewBuffer(body)))
 		w := httptest.NewRecorder()
 
 		handler.Create(w, req)
@@ -211,11 +381,11 @@ func TestEntryHandler_Create(t *testing.T) {
 func TestEntryHandler_Update(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockUC := &mockEntryUseCase{}
-		handler := NewEntryHandler(mockUC)
+		handler := NewEntryHandler(mockUC, nil)
 
 		entry := model.Entry{Title: "Updated", Slug: "updated", Body: "Body", Author: "author"}
 		body, _ := json.Marshal(entry)
-		req := httptest.NewRequest("PUT", "/entries/1", bytes.NewBuffer(body))
+		req := withCaller(httptest.NewRequest("PUT", "/entries/1", bytes.NewBuffer(body)))
 		w := httptest.NewRecorder()
 
 		handler.Update(w, req)
@@ -226,9 +396,9 @@ func TestEntryHandler_Update(t *testing.T) {
 	})
 
 	t.Run("invalid id", func(t *testing.T) {
-		handler := NewEntryHandler(&mockEntryUseCase{})
+		handler := NewEntryHandler(&mockEntryUseCase{}, nil)
 
-		req := httptest.NewRequest("PUT", "/entries/abc", nil)
+		req := withCaller(httptest.NewRequest("PUT", "/entries/abc", nil))
 		w := httptest.NewRecorder()
 
 		handler.Update(w, req)
@@ -237,14 +407,29 @@ func TestEntryHandler_Update(t *testing.T) {
 			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
 		}
 	})
+
+	t.Run("not owner", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{updateErr: usecase.ErrForbidden}, nil)
+
+		entry := model.Entry{Title: "Updated", Slug: "updated", Body: "Body"}
+		body, _ := json.Marshal(entry)
+		req := withCaller(httptest.NewRequest("PUT", "/entries/1", bytes.NewBuffer(body)))
+		w := httptest.NewRecorder()
+
+		handler.Update(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
 }
 
 func TestEntryHandler_Delete(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockUC := &mockEntryUseCase{}
-		handler := NewEntryHandler(mockUC)
+		handler := NewEntryHandler(mockUC, nil)
 
-		req := httptest.NewRequest("DELETE", "/entries/1", nil)
+		req := withCaller(httptest.NewRequest("DELETE", "/entries/1", nil))
 		w := httptest.NewRecorder()
 
 		handler.Delete(w, req)
@@ -255,15 +440,172 @@ func TestEntryHandler_Delete(t *testing.T) {
 	})
 
 	t.Run("invalid id", func(t *testing.T) {
-		handler := NewEntryHandler(&mockEntryUseCase{})
+		handler := NewEntryHandler(&mockEntryUseCase{}, nil)
+
+		req := withCaller(httptest.NewRequest("DELETE", "/entries/abc", nil))
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{deleteErr: repository.ErrNotFound}, nil)
+
+		req := withCaller(httptest.NewRequest("DELETE", "/entries/999", nil))
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{deleteErr: usecase.ErrForbidden}, nil)
 
-		req := httptest.NewRequest("DELETE", "/entries/abc", nil)
+		req := withCaller(httptest.NewRequest("DELETE", "/entries/1", nil))
 		w := httptest.NewRecorder()
 
 		handler.Delete(w, req)
 
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+}
+
+func TestEntryHandler_Search(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		results := []model.SearchResult{
+			{Entry: model.Entry{ID: 1, Title: "Gatos", Slug: "gatos"}, Rank: 0.5, Snippet: "sobre <mark>gatos</mark>"},
+		}
+		handler := NewEntryHandler(&mockEntryUseCase{results: results}, nil)
+
+		req := httptest.NewRequest("GET", "/entries/search?q=gatos", nil)
+		w := httptest.NewRecorder()
+
+		handler.Search(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{}, nil)
+
+		req := httptest.NewRequest("GET", "/entries/search?q=gatos&limit=many", nil)
+		w := httptest.NewRecorder()
+
+		handler.Search(w, req)
+
 		if w.Code != http.StatusBadRequest {
 			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
 		}
 	})
 }
+
+func TestEntryHandler_Revisions(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		mockUC := &mockEntryUseCase{revisions: []model.Revision{{EntryID: 1, Number: 1, Title: "Old"}}}
+		handler := NewEntryHandler(mockUC, nil)
+
+		req := withCaller(httptest.NewRequest("GET", "/entries/revisions/1", nil))
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.GetRevisions(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	t.Run("list forbidden", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{err: usecase.ErrForbidden}, nil)
+
+		req := withCaller(httptest.NewRequest("GET", "/entries/revisions/1", nil))
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.GetRevisions(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		mockUC := &mockEntryUseCase{}
+		handler := NewEntryHandler(mockUC, nil)
+
+		req := withCaller(httptest.NewRequest("GET", "/entries/revisions/1/diff?from=2&to=current", nil))
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.DiffRevisions(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		if mockUC.diff.From != 2 || mockUC.diff.To != 0 {
+			t.Errorf("Expected from=2 to=0, got from=%d to=%d", mockUC.diff.From, mockUC.diff.To)
+		}
+	})
+
+	t.Run("diff missing from", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{}, nil)
+
+		req := withCaller(httptest.NewRequest("GET", "/entries/revisions/1/diff?to=abc", nil))
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+
+		handler.DiffRevisions(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+
+		var response Response
+		json.NewDecoder(w.Body).Decode(&response)
+		if len(response.Details) != 2 {
+			t.Errorf("Expected 2 field errors, got %v", response.Details)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{entry: model.Entry{ID: 1, Title: "Old"}}, nil)
+
+		req := withCaller(httptest.NewRequest("POST", "/entries/revisions/1/3/restore", nil))
+		req.SetPathValue("id", "1")
+		req.SetPathValue("revision", "3")
+		w := httptest.NewRecorder()
+
+		handler.RestoreRevision(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	t.Run("restore unknown revision", func(t *testing.T) {
+		handler := NewEntryHandler(&mockEntryUseCase{updateErr: repository.ErrNotFound}, nil)
+
+		req := withCaller(httptest.NewRequest("POST", "/entries/revisions/1/9/restore", nil))
+		req.SetPathValue("id", "1")
+		req.SetPathValue("revision", "9")
+		w := httptest.NewRecorder()
+
+		handler.RestoreRevision(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
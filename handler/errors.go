@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/juanplagos/bubble/auth"
+	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+// StatusClientClosedRequest reports a request whose client went away
+// before the response was ready. Nobody reads the response, but logs do.
+const StatusClientClosedRequest = 499
+
+var errInternal = errors.New("internal server error")
+
+// StatusFromError is the single place where domain errors are mapped onto
+// HTTP status codes.
+func StatusFromError(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrInvalid),
+		errors.As(err, new(*usecase.ValidationError)):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, usecase.ErrInvalidCredentials),
+		errors.Is(err, auth.ErrInvalidToken),
+		errors.Is(err, auth.ErrExpiredToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, usecase.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, usecase.ErrUnsupportedMedia):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// WriteDomainError writes err with the status it maps to. Errors that are
+// not part of the domain vocabulary are reported as a generic internal
+// error so driver messages never reach clients; the access log keeps the
+// original.
+func WriteDomainError(w http.ResponseWriter, err error, message string) {
+	status := StatusFromError(err)
+	if status == http.StatusInternalServerError {
+		if rec := recorderFrom(w); rec != nil {
+			rec.err = err
+		}
+		err = errInternal
+	}
+	WriteError(w, status, err, message)
+}
+
+// writeDomainError logs err with what the handler was doing before writing
+// it. Failures of the server are errors and dependencies being down or slow
+// are warnings; mistakes of the client are only worth a debug line.
+func writeDomainError(logger *slog.Logger, w http.ResponseWriter, r *http.Request, err error, message string) {
+	level := slog.LevelDebug
+	switch status := StatusFromError(err); {
+	case status == http.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= 500:
+		level = slog.LevelWarn
+	}
+	logger.Log(r.Context(), level, "requisição falhou", "operation", message, "error", err)
+	WriteDomainError(w, err, message)
+}
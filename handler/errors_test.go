@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juanplagos/bubble/auth"
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+func TestStatusFromError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{repository.ErrNotFound, http.StatusNotFound},
+		{fmt.Errorf("%w: slug already exists", repository.ErrConflict), http.StatusConflict},
+		{repository.ErrInvalid, http.StatusBadRequest},
+		{repository.ErrUnavailable, http.StatusServiceUnavailable},
+		{usecase.ErrInvalidCredentials, http.StatusUnauthorized},
+		{auth.ErrExpiredToken, http.StatusUnauthorized},
+		{usecase.ErrForbidden, http.StatusForbidden},
+		{usecase.ErrTooLarge, http.StatusRequestEntityTooLarge},
+		{fmt.Errorf("%w: text/html", usecase.ErrUnsupportedMedia), http.StatusUnsupportedMediaType},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{context.Canceled, StatusClientClosedRequest},
+		{&usecase.ValidationError{Fields: []usecase.FieldError{{Field: "title", Message: "is required"}}}, http.StatusBadRequest},
+		{errors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := StatusFromError(tt.err); got != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWriteDomainError(t *testing.T) {
+	t.Run("domain error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		WriteDomainError(w, fmt.Errorf("%w: slug already exists", repository.ErrConflict), "failed to create entry")
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status code %d, got %d", http.StatusConflict, w.Code)
+		}
+
+		var response Response
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal JSON: %v", err)
+		}
+
+		if response.Error != "conflict: slug already exists" {
+			t.Errorf("Expected conflict error, got %s", response.Error)
+		}
+	})
+
+	t.Run("unknown error is hidden", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		WriteDomainError(w, errors.New("ERROR: relation \"entries\" does not exist (SQLSTATE 42P01)"), "failed to create entry")
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+
+		var response Response
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal JSON: %v", err)
+		}
+
+		if response.Error != "internal server error" {
+			t.Errorf("Expected generic error, got %s", response.Error)
+		}
+	})
+}
+
+func TestWriteDomainError_Logs(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{repository.ErrNotFound, "DEBUG"},
+		{repository.ErrUnavailable, "WARN"},
+		{errors.New("boom"), "ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := logging.New(&buf, slog.LevelDebug, "json")
+
+			writeDomainError(logger, httptest.NewRecorder(), httptest.NewRequest("GET", "/entries/7", nil), tt.err, "failed to retrieve entry")
+
+			var record map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("Expected one JSON record, got %q", buf.String())
+			}
+			if record["level"] != tt.want || record["operation"] != "failed to retrieve entry" || record["error"] != tt.err.Error() {
+				t.Errorf("Expected a %s record with the operation and error, got %v", tt.want, record)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juanplagos/bubble/feed"
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+// feedSize is how many of the latest entries a feed carries.
+const feedSize = 20
+
+// FeedConfig describes the site feeds are published for. BaseURL is the
+// public address of the API and prefixes every link in a feed.
+type FeedConfig struct {
+	BaseURL     string
+	Title       string
+	Description string
+}
+
+type FeedHandler struct {
+	entries usecase.EntryUseCase
+	authors usecase.AuthorUseCase
+	config  FeedConfig
+	logger  *slog.Logger
+}
+
+func NewFeedHandler(entries usecase.EntryUseCase, authors usecase.AuthorUseCase, config FeedConfig, logger *slog.Logger) *FeedHandler {
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	return &FeedHandler{
+		entries: entries,
+		authors: authors,
+		config:  config,
+		logger:  logging.OrDiscard(logger),
+	}
+}
+
+// Site serves /feed.xml, /atom.xml and /feed.json with the latest entries.
+func (h *FeedHandler) Site(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, "", "", model.EntryQuery{})
+}
+
+// Tag serves the feeds under /tags/{tag}/.
+func (h *FeedHandler) Tag(w http.ResponseWriter, r *http.Request) {
+	tag := r.PathValue("tag")
+	h.serve(w, r, "/tags/"+url.PathEscape(tag), "#"+tag, model.EntryQuery{Tags: []string{tag}})
+}
+
+// AuthorFeeds serves /authors/{username}/feed.xml and the other formats,
+// passing every other path on to next. The mux cannot hold those patterns
+// next to /authors/email/, so they share the /authors/ prefix route.
+func (h *FeedHandler) AuthorFeeds(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, file, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/authors/"), "/")
+		if _, known := feed.Files[file]; !ok || !known || username == "" {
+			next(w, r)
+			return
+		}
+
+		if _, err := h.authors.GetAuthorByUsername(r.Context(), username); err != nil {
+			writeDomainError(h.logger, w, r, err, "failed to retrieve author")
+			return
+		}
+		h.serve(w, r, "/authors/"+url.PathEscape(username), username, model.EntryQuery{Author: username})
+	}
+}
+
+// serve builds the feed named by the last path segment from the published
+// entries matching query. Responses carry an ETag and Last-Modified so
+// readers polling an unchanged feed get a 304 instead of the whole document.
+func (h *FeedHandler) serve(w http.ResponseWriter, r *http.Request, prefix, scope string, query model.EntryQuery) {
+	file := path.Base(r.URL.Path)
+	format, ok := feed.Files[file]
+	if !ok {
+		WriteError(w, http.StatusNotFound, nil, "feed not found")
+		return
+	}
+
+	query.Limit = feedSize
+	query.Sort = model.SortByPublishedAt
+	query.Order = model.Descending
+	page, err := h.entries.GetAllEntries(r.Context(), model.Author{}, query)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to retrieve entries")
+		return
+	}
+
+	f := h.build(page.Entries, prefix, scope, file)
+	body, err := feed.Encode(format, f)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to build feed")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	// An empty feed has no meaningful modification time; ServeContent skips
+	// Last-Modified for the zero time and relies on the ETag alone.
+	var modified time.Time
+	if len(f.Items) > 0 {
+		modified = f.Updated
+	}
+	http.ServeContent(w, r, file, modified, bytes.NewReader(body))
+}
+
+func (h *FeedHandler) build(entries []model.Entry, prefix, scope, file string) feed.Feed {
+	f := feed.Feed{
+		Title:       h.config.Title,
+		Description: h.config.Description,
+		Link:        h.config.BaseURL + "/entries",
+		FeedURL:     h.config.BaseURL + prefix + "/" + file,
+		// Feeds without entries need a stable date so their ETag stays put.
+		Updated: time.Unix(0, 0).UTC(),
+	}
+	if scope != "" {
+		f.Title += " — " + scope
+	}
+
+	for _, e := range entries {
+		// Items are identified by entry ID so renaming a slug does not make
+		// readers show the entry again as new.
+		item := feed.Item{
+			ID:      h.config.BaseURL + "/entries/" + strconv.Itoa(e.ID),
+			Title:   e.Title,
+			Link:    h.config.BaseURL + "/entries/slug/" + e.Slug,
+			Summary: e.Excerpt,
+			Content: e.BodyHTML,
+			Author:  e.Author,
+			Tags:    e.Tags,
+			Updated: e.UpdatedAt,
+		}
+		if e.PublishedAt != nil {
+			item.Published = *e.PublishedAt
+		}
+		if item.Updated.Before(item.Published) {
+			item.Updated = item.Published
+		}
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+)
+
+func feedEntries() []model.Entry {
+	published := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []model.Entry{{
+		ID:          7,
+		Title:       "Olá",
+		Slug:        "ola",
+		Author:      "juan",
+		BodyHTML:    "<p>Olá</p>",
+		Excerpt:     "Olá",
+		Tags:        []string{"go"},
+		PublishedAt: &published,
+		UpdatedAt:   published.Add(time.Hour),
+	}}
+}
+
+func TestFeedHandler_Site(t *testing.T) {
+	formats := map[string]string{
+		"/feed.xml":  "application/rss+xml; charset=utf-8",
+		"/atom.xml":  "application/atom+xml; charset=utf-8",
+		"/feed.json": "application/feed+json; charset=utf-8",
+	}
+	for path, contentType := range formats {
+		t.Run(path, func(t *testing.T) {
+			mockUC := &mockEntryUseCase{entries: feedEntries()}
+			handler := NewFeedHandler(mockUC, &mockAuthorUseCase{}, FeedConfig{BaseURL: "https://blog.example/", Title: "bubble"}, nil)
+
+			req := httptest.NewRequest("GET", path, nil)
+			w := httptest.NewRecorder()
+
+			handler.Site(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != contentType {
+				t.Errorf("Expected content type %q, got %q", contentType, got)
+			}
+
+			if w.Header().Get("ETag") == "" || w.Header().Get("Last-Modified") != "Sat, 01 Mar 2025 13:00:00 GMT" {
+				t.Errorf("Expected validators, got %v", w.Header())
+			}
+
+			if !strings.Contains(w.Body.String(), "https://blog.example/entries/slug/ola") {
+				t.Errorf("Expected absolute entry link, got\n%s", w.Body.String())
+			}
+
+			if mockUC.query.Sort != model.SortByPublishedAt || mockUC.query.Limit != feedSize {
+				t.Errorf("Expected latest published entries, got %+v", mockUC.query)
+			}
+		})
+	}
+}
+
+func TestFeedHandler_ConditionalGet(t *testing.T) {
+	handler := NewFeedHandler(&mockEntryUseCase{entries: feedEntries()}, &mockAuthorUseCase{}, FeedConfig{BaseURL: "https://blog.example"}, nil)
+
+	w := httptest.NewRecorder()
+	handler.Site(w, httptest.NewRequest("GET", "/feed.xml", nil))
+	etag := w.Header().Get("ETag")
+
+	t.Run("matching etag", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/feed.xml", nil)
+		req.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+
+		handler.Site(w, req)
+
+		if w.Code != http.StatusNotModified {
+			t.Errorf("Expected status %d, got %d", http.StatusNotModified, w.Code)
+		}
+	})
+
+	t.Run("stale etag", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/feed.xml", nil)
+		req.Header.Set("If-None-Match", `"stale"`)
+		w := httptest.NewRecorder()
+
+		handler.Site(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+}
+
+func TestFeedHandler_Tag(t *testing.T) {
+	mockUC := &mockEntryUseCase{entries: feedEntries()}
+	handler := NewFeedHandler(mockUC, &mockAuthorUseCase{}, FeedConfig{BaseURL: "https://blog.example", Title: "bubble"}, nil)
+
+	req := httptest.NewRequest("GET", "/tags/go/atom.xml", nil)
+	req.SetPathValue("tag", "go")
+	w := httptest.NewRecorder()
+
+	handler.Tag(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	if len(mockUC.query.Tags) != 1 || mockUC.query.Tags[0] != "go" {
+		t.Errorf("Expected tag filter, got %+v", mockUC.query)
+	}
+
+	if !strings.Contains(w.Body.String(), "https://blog.example/tags/go/atom.xml") {
+		t.Errorf("Expected self link, got\n%s", w.Body.String())
+	}
+}
+
+func TestFeedHandler_AuthorFeeds(t *testing.T) {
+	t.Run("feed", func(t *testing.T) {
+		mockUC := &mockEntryUseCase{entries: feedEntries()}
+		handler := NewFeedHandler(mockUC, &mockAuthorUseCase{author: model.Author{Username: "juan"}}, FeedConfig{}, nil)
+		next := func(w http.ResponseWriter, r *http.Request) { t.Error("Expected feed, got next handler") }
+
+		w := httptest.NewRecorder()
+		handler.AuthorFeeds(next)(w, httptest.NewRequest("GET", "/authors/juan/feed.json", nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		if mockUC.query.Author != "juan" {
+			t.Errorf("Expected author filter, got %+v", mockUC.query)
+		}
+	})
+
+	t.Run("other paths", func(t *testing.T) {
+		handler := NewFeedHandler(&mockEntryUseCase{}, &mockAuthorUseCase{}, FeedConfig{}, nil)
+		called := false
+		next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+		for _, path := range []string{"/authors/juan", "/authors/juan/entries"} {
+			called = false
+			handler.AuthorFeeds(next)(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+			if !called {
+				t.Errorf("Expected %s to reach next handler", path)
+			}
+		}
+	})
+
+	t.Run("unknown author", func(t *testing.T) {
+		handler := NewFeedHandler(&mockEntryUseCase{}, &mockAuthorUseCase{err: repository.ErrNotFound}, FeedConfig{}, nil)
+		next := func(w http.ResponseWriter, r *http.Request) {}
+
+		w := httptest.NewRecorder()
+		handler.AuthorFeeds(next)(w, httptest.NewRequest("GET", "/authors/ghost/feed.xml", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
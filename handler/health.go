@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	statusOK       = "ok"
+	statusFailing  = "failing"
+	statusDraining = "draining"
+)
+
+// HealthCheck reports whether something the server needs to answer
+// requests is usable.
+type HealthCheck struct {
+	Name  string
+	Check func(context.Context) error
+}
+
+// HealthHandler answers the orchestrator's probes. Liveness only says the
+// process is up; readiness runs every check within timeout and fails as
+// soon as the server starts draining, so traffic moves elsewhere before
+// the listener closes.
+type HealthHandler struct {
+	checks   []HealthCheck
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+type healthResponse struct {
+	Status     string                     `json:"status"`
+	Components map[string]componentHealth `json:"components,omitempty"`
+}
+
+type componentHealth struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func NewHealthHandler(timeout time.Duration, checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// Drain makes readiness fail from now on.
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	WriteJSON(w, http.StatusOK, healthResponse{Status: statusOK})
+}
+
+// Ready runs the checks concurrently, so one hanging dependency costs at
+// most timeout. While draining the checks are skipped altogether.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if h.draining.Load() {
+		WriteJSON(w, http.StatusServiceUnavailable, healthResponse{
+			Status:     statusFailing,
+			Components: map[string]componentHealth{"server": {Status: statusDraining}},
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	results := make([]componentHealth, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Go(func() {
+			results[i] = componentHealth{Status: statusOK}
+			if err := c.Check(ctx); err != nil {
+				results[i] = componentHealth{Status: statusFailing, Error: err.Error()}
+			}
+		})
+	}
+	wg.Wait()
+
+	resp := healthResponse{
+		Status:     statusOK,
+		Components: map[string]componentHealth{"server": {Status: statusOK}},
+	}
+	for i, c := range h.checks {
+		resp.Components[c.Name] = results[i]
+		if results[i].Status != statusOK {
+			resp.Status = statusFailing
+		}
+	}
+
+	status := http.StatusOK
+	if resp.Status != statusOK {
+		status = http.StatusServiceUnavailable
+	}
+	WriteJSON(w, status, resp)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthHandler(t *testing.T) {
+	ok := HealthCheck{Name: "database", Check: func(context.Context) error { return nil }}
+	broken := HealthCheck{Name: "migrations", Check: func(context.Context) error { return errors.New("1 pending") }}
+	hanging := HealthCheck{Name: "database", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	tests := []struct {
+		name       string
+		checks     []HealthCheck
+		drain      bool
+		wantStatus int
+		want       map[string]string
+	}{
+		{"all ok", []HealthCheck{ok}, false, http.StatusOK, map[string]string{"server": "ok", "database": "ok"}},
+		{"failing check", []HealthCheck{ok, broken}, false, http.StatusServiceUnavailable, map[string]string{"database": "ok", "migrations": "failing"}},
+		{"check past the timeout", []HealthCheck{hanging}, false, http.StatusServiceUnavailable, map[string]string{"database": "failing"}},
+		{"draining", []HealthCheck{ok}, true, http.StatusServiceUnavailable, map[string]string{"server": "draining"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandler(10*time.Millisecond, tt.checks...)
+			if tt.drain {
+				h.Drain()
+			}
+			w := httptest.NewRecorder()
+
+			h.Ready(w, httptest.NewRequest("GET", "/readyz", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			var resp healthResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			for name, status := range tt.want {
+				if got := resp.Components[name].Status; got != status {
+					t.Errorf("Expected %s to be %q, got %q", name, status, got)
+				}
+			}
+		})
+	}
+
+	t.Run("liveness ignores the checks", func(t *testing.T) {
+		h := NewHealthHandler(time.Second, broken)
+		h.Drain()
+		w := httptest.NewRecorder()
+
+		h.Live(w, httptest.NewRequest("GET", "/healthz", nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/juanplagos/bubble/logging"
+)
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+// RequestID tags every request with an ID, taken from the X-Request-ID
+// header when a proxy in front already assigned one, and echoes it in the
+// response so clients can quote it when reporting a problem.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID accepts IDs of visible ASCII only, so a client cannot
+// forge log lines or bloat them.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AccessLog writes one record per request once it has been served.
+// Handlers further down fill in what only they know, the route matched,
+// the caller and the error behind a 500, through the response writer.
+func AccessLog(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &accessRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", rec.route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int64("bytes", rec.bytes),
+		}
+		if rec.author != "" {
+			attrs = append(attrs, slog.String("author", rec.author))
+		}
+		if rec.err != nil {
+			attrs = append(attrs, slog.String("error", rec.err.Error()))
+		}
+		logger.LogAttrs(r.Context(), level, "requisição", attrs...)
+	})
+}
+
+// RecordRoute notes the pattern mux matched for the access log. The mux
+// sets it on the request it is handed, so it is read back after serving.
+func RecordRoute(mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if rec := recorderFrom(w); rec != nil && rec.route == "" {
+			rec.route = r.Pattern
+		}
+	})
+}
+
+type accessRecorder struct {
+	http.ResponseWriter
+	status  int
+	bytes   int64
+	written bool
+
+	route  string
+	author string
+	err    error
+}
+
+func (rec *accessRecorder) WriteHeader(status int) {
+	if !rec.written {
+		rec.status, rec.written = status, true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *accessRecorder) Write(b []byte) (int, error) {
+	rec.written = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+func (rec *accessRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// recorderFrom finds the access recorder behind w, if the request is
+// being logged at all.
+func recorderFrom(w http.ResponseWriter) *accessRecorder {
+	for {
+		switch t := w.(type) {
+		case *accessRecorder:
+			return t
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/model"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+	}))
+
+	t.Run("kept from the client", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/entries", nil)
+		req.Header.Set("X-Request-ID", "abc-123")
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, req)
+
+		if seen != "abc-123" || w.Header().Get("X-Request-ID") != "abc-123" {
+			t.Errorf("Expected the client's ID, got %q and %q", seen, w.Header().Get("X-Request-ID"))
+		}
+	})
+
+	t.Run("generated when missing or invalid", func(t *testing.T) {
+		for _, id := range []string{"", "bad id\n", strings.Repeat("a", 200)} {
+			req := httptest.NewRequest("GET", "/entries", nil)
+			req.Header.Set("X-Request-ID", id)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			if len(seen) != 32 || seen == id || w.Header().Get("X-Request-ID") != seen {
+				t.Errorf("Expected a generated ID for %q, got %q", id, seen)
+			}
+		}
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo, "json")
+
+	authHandler := NewAuthHandler(&mockAuthUseCase{author: model.Author{Username: "author"}}, nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /entries/{id}", authHandler.RequireAuth(func(w http.ResponseWriter, r *http.Request) {
+		WriteDomainError(w, errors.New("relation \"entries\" does not exist"), "failed to retrieve entry")
+	}))
+	h := RequestID(AccessLog(logger, RecordRoute(mux)))
+
+	req := httptest.NewRequest("GET", "/entries/7", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("X-Request-ID", "req-1")
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q", buf.String())
+	}
+
+	want := map[string]any{
+		"level":      "ERROR",
+		"method":     "GET",
+		"route":      "GET /entries/{id}",
+		"path":       "/entries/7",
+		"status":     float64(http.StatusInternalServerError),
+		"bytes":      float64(w.Body.Len()),
+		"author":     "author",
+		"error":      `relation "entries" does not exist`,
+		"request_id": "req-1",
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("Expected %s to be %v, got %v", key, value, record[key])
+		}
+	}
+	if _, ok := record["latency_ms"]; !ok {
+		t.Error("Expected latency to be logged")
+	}
+	if strings.Contains(w.Body.String(), "relation") {
+		t.Error("Expected the error to stay out of the response")
+	}
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+// multipartOverhead is the room left for multipart framing on top of the
+// largest file accepted.
+const multipartOverhead = 1 << 20
+
+// inlineTypes are the uploads browsers may display. Anything else, such as
+// a PDF or an HTML page, is sent as a download so it never runs or renders
+// under the API's origin.
+var inlineTypes = map[string]bool{"image/jpeg": true, "image/png": true, "image/gif": true, "image/webp": true}
+
+type MediaHandler struct {
+	useCase usecase.MediaUseCase
+	logger  *slog.Logger
+}
+
+func NewMediaHandler(useCase usecase.MediaUseCase, logger *slog.Logger) *MediaHandler {
+	return &MediaHandler{
+		useCase: useCase,
+		logger:  logging.OrDiscard(logger),
+	}
+}
+
+// Upload takes a multipart/form-data body with the file in a part named
+// "file". Parts are streamed, so the upload never sits in memory or in a
+// temporary file before reaching storage.
+func (h *MediaHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, usecase.MaxMediaSize+multipartOverhead)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "expected a multipart/form-data body")
+		return
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			WriteError(w, http.StatusBadRequest, nil, "file is required")
+			return
+		}
+		if err != nil {
+			h.writeUploadError(w, r, err)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		media, err := h.useCase.Upload(r.Context(), caller, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			h.writeUploadError(w, r, err)
+			return
+		}
+		WriteSuccess(w, http.StatusCreated, withMediaURL(media), "file uploaded successfully")
+		return
+	}
+}
+
+func (h *MediaHandler) writeUploadError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.As(err, new(*http.MaxBytesError)) {
+		err = usecase.ErrTooLarge
+	}
+	writeDomainError(h.logger, w, r, err, "failed to upload file")
+}
+
+// Serve sends an uploaded file, or one of its variants under
+// /media/{id}/{variant}. Content under a URL never changes, so it may be
+// cached indefinitely; the original's checksum doubles as a strong ETag,
+// variants being derived from it.
+func (h *MediaHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid media ID")
+		return
+	}
+
+	variant := r.PathValue("variant")
+	media, file, err := h.useCase.GetMedia(r.Context(), id, variant)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to retrieve media")
+		return
+	}
+	defer file.Close()
+
+	etag := media.Checksum
+	disposition := "attachment"
+	if inlineTypes[media.ContentType] {
+		disposition = "inline"
+	}
+	disposition = mime.FormatMediaType(disposition, map[string]string{"filename": media.Filename})
+	if variant != "" {
+		// The original's name would carry the wrong extension.
+		etag += "-" + variant
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", media.ContentType)
+	w.Header().Set("ETag", `"`+etag+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", disposition)
+	http.ServeContent(w, r, media.Filename, media.CreatedAt, file)
+}
+
+// GetAll lists the caller's own uploads.
+func (h *MediaHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	media, err := h.useCase.ListMedia(r.Context(), caller)
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to retrieve media")
+		return
+	}
+
+	for i := range media {
+		media[i] = withMediaURL(media[i])
+	}
+	WriteSuccess(w, http.StatusOK, media, "media retrieved successfully")
+}
+
+func (h *MediaHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	caller, ok := callerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err, "invalid media ID")
+		return
+	}
+
+	if err := h.useCase.DeleteMedia(r.Context(), caller, id); err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to delete media")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, nil, "media deleted successfully")
+}
+
+// withMediaURL fills in the paths entry bodies should use to embed media
+// and, for images, a srcset listing every width available.
+func withMediaURL(media model.Media) model.Media {
+	media.URL = "/media/" + strconv.Itoa(media.ID)
+
+	var srcset []string
+	variants := make([]model.MediaVariant, len(media.Variants))
+	for i, v := range media.Variants {
+		v.URL = media.URL + "/" + v.Name
+		variants[i] = v
+		srcset = append(srcset, v.URL+" "+strconv.Itoa(v.Width)+"w")
+	}
+	media.Variants = variants
+
+	if media.Width > 0 {
+		media.SrcSet = strings.Join(append(srcset, media.URL+" "+strconv.Itoa(media.Width)+"w"), ", ")
+	}
+	return media
+}
@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+type mockMediaUseCase struct {
+	media    model.Media
+	content  string
+	filename string
+	variant  string
+	err      error
+}
+
+func (m *mockMediaUseCase) Upload(ctx context.Context, caller model.Author, filename string, r io.Reader) (model.Media, error) {
+	m.filename = filename
+	n, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return model.Media{}, err
+	}
+	media := m.media
+	media.Size = n
+	return media, m.err
+}
+
+func (m *mockMediaUseCase) GetMedia(ctx context.Context, id int, variant string) (model.Media, io.ReadSeekCloser, error) {
+	m.variant = variant
+	if m.err != nil {
+		return model.Media{}, nil, m.err
+	}
+	return m.media, nopSeekCloser{strings.NewReader(m.content)}, nil
+}
+
+func (m *mockMediaUseCase) ListMedia(ctx context.Context, caller model.Author) ([]model.Media, error) {
+	return []model.Media{m.media}, m.err
+}
+
+func (m *mockMediaUseCase) DeleteMedia(ctx context.Context, caller model.Author, id int) error {
+	return m.err
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error { return nil }
+
+func multipartBody(t *testing.T, field, filename string, content io.Reader) (*bytes.Buffer, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("alt", "ignored")
+	part, err := mw.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(part, content)
+	mw.Close()
+	return &buf, mw.FormDataContentType()
+}
+
+func TestMediaHandler_Upload(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUC := &mockMediaUseCase{media: model.Media{ID: 4, ContentType: "image/png"}}
+		handler := NewMediaHandler(mockUC, nil)
+
+		body, contentType := multipartBody(t, "file", "gato.png", strings.NewReader("png data"))
+		req := withCaller(httptest.NewRequest("POST", "/media", body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body)
+		}
+
+		if mockUC.filename != "gato.png" || !strings.Contains(w.Body.String(), `"url":"/media/4"`) {
+			t.Errorf("Unexpected response %s", w.Body)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		handler := NewMediaHandler(&mockMediaUseCase{}, nil)
+
+		body, contentType := multipartBody(t, "other", "gato.png", strings.NewReader("png data"))
+		req := withCaller(httptest.NewRequest("POST", "/media", body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("not multipart", func(t *testing.T) {
+		handler := NewMediaHandler(&mockMediaUseCase{}, nil)
+
+		req := withCaller(httptest.NewRequest("POST", "/media", strings.NewReader("{}")))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		handler := NewMediaHandler(&mockMediaUseCase{}, nil)
+
+		big := io.LimitReader(zeros{}, usecase.MaxMediaSize+multipartOverhead+1)
+		body, contentType := multipartBody(t, "file", "big.png", big)
+		req := withCaller(httptest.NewRequest("POST", "/media", body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		handler := NewMediaHandler(&mockMediaUseCase{err: usecase.ErrUnsupportedMedia}, nil)
+
+		body, contentType := multipartBody(t, "file", "page.html", strings.NewReader("<html>"))
+		req := withCaller(httptest.NewRequest("POST", "/media", body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		if w.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected status %d, got %d", http.StatusUnsupportedMediaType, w.Code)
+		}
+	})
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestMediaHandler_Serve(t *testing.T) {
+	media := model.Media{
+		ID:          4,
+		Filename:    "gato.png",
+		ContentType: "image/png",
+		Checksum:    "abc123",
+		CreatedAt:   time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		handler := NewMediaHandler(&mockMediaUseCase{media: media, content: "png data"}, nil)
+
+		req := httptest.NewRequest("GET", "/media/4", nil)
+		req.SetPathValue("id", "4")
+		w := httptest.NewRecorder()
+
+		handler.Serve(w, req)
+
+		if w.Code != http.StatusOK || w.Body.String() != "png data" {
+			t.Fatalf("Expected the file, got %d %q", w.Code, w.Body)
+		}
+
+		headers := map[string]string{
+			"Content-Type":           "image/png",
+			"ETag":                   `"abc123"`,
+			"Cache-Control":          "public, max-age=31536000, immutable",
+			"X-Content-Type-Options": "nosniff",
+			"Content-Disposition":    "inline; filename=gato.png",
+			"Last-Modified":          "Sat, 01 Mar 2025 12:00:00 GMT",
+		}
+		for name, want := range headers {
+			if got := w.Header().Get(name); got != want {
+				t.Errorf("Expected %s %q, got %q", name, want, got)
+			}
+		}
+	})
+
+	t.Run("other types are downloaded", func(t *testing.T) {
+		pdf := media
+		pdf.Filename, pdf.ContentType = "nota.pdf", "application/pdf"
+		handler := NewMediaHandler(&mockMediaUseCase{media: pdf, content: "%PDF"}, nil)
+
+		req := httptest.NewRequest("GET", "/media/4", nil)
+		req.SetPathValue("id", "4")
+		w := httptest.NewRecorder()
+
+		handler.Serve(w, req)
+
+		if got := w.Header().Get("Content-Disposition"); got != "attachment; filename=nota.pdf" {
+			t.Errorf("Expected an attachment, got %q", got)
+		}
+		if got := w.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+			t.Errorf("Expected nosniff, got %q", got)
+		}
+	})
+
+	t.Run("variant", func(t *testing.T) {
+		mockUC := &mockMediaUseCase{media: media, content: "jpeg data"}
+		handler := NewMediaHandler(mockUC, nil)
+
+		req := httptest.NewRequest("GET", "/media/4/thumbnail", nil)
+		req.SetPathValue("id", "4")
+		req.SetPathValue("variant", "thumbnail")
+		w := httptest.NewRecorder()
+
+		handler.Serve(w, req)
+
+		if w.Code != http.StatusOK || mockUC.variant != "thumbnail" {
+			t.Fatalf("Expected the variant, got %d for %q", w.Code, mockUC.variant)
+		}
+
+		if got := w.Header().Get("ETag"); got != `"abc123-thumbnail"` {
+			t.Errorf("Expected a per-variant ETag, got %q", got)
+		}
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		handler := NewMediaHandler(&mockMediaUseCase{media: media, content: "png data"}, nil)
+
+		req := httptest.NewRequest("GET", "/media/4", nil)
+		req.SetPathValue("id", "4")
+		req.Header.Set("If-None-Match", `"abc123"`)
+		w := httptest.NewRecorder()
+
+		handler.Serve(w, req)
+
+		if w.Code != http.StatusNotModified {
+			t.Errorf("Expected status %d, got %d", http.StatusNotModified, w.Code)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		handler := NewMediaHandler(&mockMediaUseCase{err: repository.ErrNotFound}, nil)
+
+		req := httptest.NewRequest("GET", "/media/9", nil)
+		req.SetPathValue("id", "9")
+		w := httptest.NewRecorder()
+
+		handler.Serve(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
+
+func TestWithMediaURL(t *testing.T) {
+	media := withMediaURL(model.Media{
+		ID:    4,
+		Width: 2000,
+		Variants: []model.MediaVariant{
+			{Name: "thumbnail", Width: 320},
+			{Name: "large", Width: 1600},
+		},
+	})
+
+	if media.URL != "/media/4" || media.Variants[0].URL != "/media/4/thumbnail" {
+		t.Errorf("Unexpected URLs %+v", media)
+	}
+
+	want := "/media/4/thumbnail 320w, /media/4/large 1600w, /media/4 2000w"
+	if media.SrcSet != want {
+		t.Errorf("Expected srcset %q, got %q", want, media.SrcSet)
+	}
+
+	if pdf := withMediaURL(model.Media{ID: 5}); pdf.SrcSet != "" {
+		t.Errorf("Expected no srcset for documents, got %q", pdf.SrcSet)
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/juanplagos/bubble/metrics"
+)
+
+// unmatchedRoute labels requests no route pattern matched, such as CORS
+// preflights answered before the mux, so stray paths cannot blow up the
+// number of series.
+const unmatchedRoute = "unmatched"
+
+// Instrument counts and times requests per method and route pattern. Place
+// it outside RecordRoute, as with AccessLog.
+func Instrument(reg *metrics.Registry, next http.Handler) http.Handler {
+	requests := reg.NewCounter("http_requests_total", "HTTP requests served, by route and status.", "method", "route", "status")
+	duration := reg.NewHistogram("http_request_duration_seconds", "Time taken to serve HTTP requests, by route.", metrics.DefaultBuckets, "method", "route")
+	var inFlight metrics.Gauge
+	reg.NewGaugeFunc("http_requests_in_flight", "HTTP requests being served.", inFlight.Value)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		rec := recorderFrom(w)
+		if rec == nil {
+			rec = &accessRecorder{ResponseWriter: w, status: http.StatusOK}
+			w = rec
+		}
+
+		next.ServeHTTP(w, r)
+
+		route := rec.route
+		if route == "" {
+			route = unmatchedRoute
+		}
+		requests.Inc(r.Method, route, strconv.Itoa(rec.status))
+		duration.Observe(time.Since(start).Seconds(), r.Method, route)
+	})
+}
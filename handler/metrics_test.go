@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juanplagos/bubble/metrics"
+)
+
+func TestInstrument(t *testing.T) {
+	reg := metrics.NewRegistry()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /entries/{id}", func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, http.StatusNotFound, nil, "entry not found")
+	})
+	h := Instrument(reg, RecordRoute(mux))
+
+	for _, path := range []string{"/entries/1", "/entries/2", "/nowhere"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	reg.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	out := w.Body.String()
+
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="GET /entries/{id}",status="404"} 2`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="GET /entries/{id}"} 2`,
+		"http_requests_in_flight 0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+// parseEntryQuery reads the listing parameters of GET /entries. Values are
+// only checked for syntax here; defaults and ranges belong to the usecase.
+func parseEntryQuery(values url.Values) (model.EntryQuery, error) {
+	var fields []usecase.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, usecase.FieldError{Field: field, Message: message})
+	}
+
+	query := model.EntryQuery{
+		Cursor:   values.Get("cursor"),
+		Sort:     model.EntrySort(values.Get("sort")),
+		Order:    model.SortOrder(values.Get("order")),
+		Author:   values.Get("author"),
+		Status:   model.EntryStatus(values.Get("status")),
+		TagMatch: model.TagMatch(values.Get("tag_match")),
+	}
+
+	// Tags may be repeated (?tag=go&tag=sql) or comma separated (?tag=go,sql).
+	for _, raw := range values["tag"] {
+		for _, tag := range strings.Split(raw, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				query.Tags = append(query.Tags, tag)
+			}
+		}
+	}
+
+	query.Limit, query.Offset = parseLimitOffset(values, invalid)
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &query.From}, {"to", &query.To}} {
+		raw := values.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := parseTime(raw)
+		if err != nil {
+			invalid(p.name, "must be a date (2006-01-02) or an RFC 3339 timestamp")
+			continue
+		}
+		*p.dst = &t
+	}
+
+	if len(fields) > 0 {
+		return query, &usecase.ValidationError{Fields: fields}
+	}
+	return query, nil
+}
+
+func parseTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+func parseSearchQuery(values url.Values) (model.SearchQuery, error) {
+	var fields []usecase.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, usecase.FieldError{Field: field, Message: message})
+	}
+
+	query := model.SearchQuery{Text: values.Get("q")}
+	query.Limit, query.Offset = parseLimitOffset(values, invalid)
+
+	if len(fields) > 0 {
+		return query, &usecase.ValidationError{Fields: fields}
+	}
+	return query, nil
+}
+
+// parseCommentQuery reads the parameters of the moderation queue.
+func parseCommentQuery(values url.Values) (model.CommentQuery, error) {
+	var fields []usecase.FieldError
+	invalid := func(field, message string) {
+		fields = append(fields, usecase.FieldError{Field: field, Message: message})
+	}
+
+	query := model.CommentQuery{Status: model.CommentStatus(values.Get("status"))}
+	query.Limit, query.Offset = parseLimitOffset(values, invalid)
+
+	if len(fields) > 0 {
+		return query, &usecase.ValidationError{Fields: fields}
+	}
+	return query, nil
+}
+
+func parseLimitOffset(values url.Values, invalid func(field, message string)) (limit, offset int) {
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"limit", &limit}, {"offset", &offset}} {
+		raw := values.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			invalid(p.name, "must be an integer")
+			continue
+		}
+		*p.dst = n
+	}
+	return limit, offset
+}
+
+// parseRevisionRange reads the from/to parameters of the revision diff. Both
+// take a revision number or "current"; to defaults to the current version.
+func parseRevisionRange(values url.Values) (from, to int, err error) {
+	var fields []usecase.FieldError
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"from", &from}, {"to", &to}} {
+		raw := values.Get(p.name)
+		if raw == "" && p.name == "from" {
+			fields = append(fields, usecase.FieldError{Field: p.name, Message: "is required"})
+			continue
+		}
+		if raw == "" || raw == "current" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			fields = append(fields, usecase.FieldError{Field: p.name, Message: `must be a revision number or "current"`})
+			continue
+		}
+		*p.dst = n
+	}
+
+	if len(fields) > 0 {
+		return 0, 0, &usecase.ValidationError{Fields: fields}
+	}
+	return from, to, nil
+}
@@ -2,14 +2,26 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"github.com/juanplagos/bubble/usecase"
 )
 
 type Response struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-	Message string      `json:"message,omitempty"`
+	Success    bool                 `json:"success"`
+	Data       interface{}          `json:"data,omitempty"`
+	Pagination *Pagination          `json:"pagination,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	Message    string               `json:"message,omitempty"`
+	Details    []usecase.FieldError `json:"details,omitempty"`
+}
+
+type Pagination struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func WriteJSON(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -27,6 +39,16 @@ func WriteSuccess(w http.ResponseWriter, statusCode int, data interface{}, messa
 	WriteJSON(w, statusCode, response)
 }
 
+func WritePaginated(w http.ResponseWriter, statusCode int, data interface{}, pagination Pagination, message string) {
+	response := Response{
+		Success:    true,
+		Data:       data,
+		Pagination: &pagination,
+		Message:    message,
+	}
+	WriteJSON(w, statusCode, response)
+}
+
 func WriteError(w http.ResponseWriter, statusCode int, err error, message string) {
 	response := Response{
 		Success: false,
@@ -35,5 +57,10 @@ func WriteError(w http.ResponseWriter, statusCode int, err error, message string
 	if err != nil {
 		response.Error = err.Error()
 	}
+	var validationErr *usecase.ValidationError
+	if errors.As(err, &validationErr) {
+		response.Error = "validation failed"
+		response.Details = validationErr.Fields
+	}
 	WriteJSON(w, statusCode, response)
 }
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/usecase"
+)
+
+type TagHandler struct {
+	useCase usecase.TagUseCase
+	logger  *slog.Logger
+}
+
+func NewTagHandler(useCase usecase.TagUseCase, logger *slog.Logger) *TagHandler {
+	return &TagHandler{
+		useCase: useCase,
+		logger:  logging.OrDiscard(logger),
+	}
+}
+
+func (h *TagHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.useCase.GetAllTags(r.Context())
+	if err != nil {
+		writeDomainError(h.logger, w, r, err, "failed to retrieve tags")
+		return
+	}
+	WriteSuccess(w, http.StatusOK, tags, "tags retrieved successfully")
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+type mockTagUseCase struct {
+	tags []model.Tag
+	err  error
+}
+
+func (m *mockTagUseCase) GetAllTags(ctx context.Context) ([]model.Tag, error) {
+	return m.tags, m.err
+}
+
+func TestTagHandler_GetAll(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUC := &mockTagUseCase{tags: []model.Tag{{Name: "go", Count: 3}, {Name: "sql", Count: 1}}}
+		handler := NewTagHandler(mockUC, nil)
+
+		req := httptest.NewRequest("GET", "/tags", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		var response struct {
+			Data []model.Tag `json:"data"`
+		}
+		json.NewDecoder(w.Body).Decode(&response)
+		if len(response.Data) != 2 || response.Data[0].Count != 3 {
+			t.Errorf("Expected tags with counts, got %+v", response.Data)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		handler := NewTagHandler(&mockTagUseCase{err: errors.New("database error")}, nil)
+
+		req := httptest.NewRequest("GET", "/tags", nil)
+		w := httptest.NewRecorder()
+
+		handler.GetAll(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+// Package imaging turns uploaded images into files that are safe and cheap
+// to serve: metadata such as EXIF and GPS positions is removed from the
+// original and smaller variants are derived for responsive layouts.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels bounds the size of images that are decoded. A few kilobytes of
+// compressed data can describe an image that takes gigabytes to decode;
+// one at the limit still takes up to about 170MB once decoded and turned
+// upright.
+const MaxPixels = 24_000_000
+
+const jpegQuality = 85
+
+var (
+	ErrTooLarge = errors.New("image too large")
+	ErrInvalid  = errors.New("invalid image")
+)
+
+// Size names a variant and bounds its width. Variants keep the original's
+// aspect ratio and are never wider than the original.
+type Size struct {
+	Name     string
+	MaxWidth int
+}
+
+// Sizes are the variants derived from every uploaded image.
+var Sizes = []Size{
+	{Name: "thumbnail", MaxWidth: 320},
+	{Name: "medium", MaxWidth: 800},
+	{Name: "large", MaxWidth: 1600},
+}
+
+type Variant struct {
+	Name        string
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
+
+// Result is an image ready to be stored: Original is the uploaded file
+// without metadata and Width and Height are its dimensions as displayed.
+type Result struct {
+	Original []byte
+	Width    int
+	Height   int
+	Variants []Variant
+}
+
+// Supported reports whether Process can handle a content type.
+func Supported(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// Process strips metadata from an image and derives the given sizes from
+// it. JPEGs are turned upright according to their EXIF orientation, which
+// would otherwise be lost along with the rest of the metadata; only then
+// is the original re-encoded. Variants of JPEGs are JPEGs; those of PNGs,
+// GIFs and WebPs are PNGs so transparency survives.
+func Process(data []byte, contentType string, sizes []Size) (Result, error) {
+	if !Supported(contentType) {
+		return Result{}, fmt.Errorf("%w: unsupported type %s", ErrInvalid, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return Result{}, ErrInvalid
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return Result{}, ErrTooLarge
+	}
+
+	original := data
+	orientation := 1
+	switch contentType {
+	case "image/jpeg":
+		orientation = exifOrientation(data)
+		if original, err = stripJPEG(data); err != nil {
+			return Result{}, err
+		}
+	case "image/png":
+		if original, err = stripPNG(data); err != nil {
+			return Result{}, err
+		}
+	case "image/gif":
+		if original, err = stripGIF(data); err != nil {
+			return Result{}, err
+		}
+	case "image/webp":
+		if original, err = stripWebP(data); err != nil {
+			return Result{}, err
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	if orientation != 1 {
+		img = orient(img, orientation)
+		if original, err = encode(img, "image/jpeg"); err != nil {
+			return Result{}, err
+		}
+	}
+
+	bounds := img.Bounds()
+	result := Result{Original: original, Width: bounds.Dx(), Height: bounds.Dy()}
+
+	variantType := "image/png"
+	if contentType == "image/jpeg" {
+		variantType = "image/jpeg"
+	}
+
+	for _, size := range sizes {
+		if size.MaxWidth >= result.Width {
+			continue
+		}
+
+		resized := resize(img, size.MaxWidth)
+		out, err := encode(resized, variantType)
+		if err != nil {
+			return Result{}, err
+		}
+
+		result.Variants = append(result.Variants, Variant{
+			Name:        size.Name,
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+			ContentType: variantType,
+			Data:        out,
+		})
+	}
+	return result, nil
+}
+
+func resize(img image.Image, width int) image.Image {
+	b := img.Bounds()
+	height := max(1, (b.Dy()*width+b.Dx()/2)/b.Dx())
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
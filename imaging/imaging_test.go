@@ -0,0 +1,290 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	// A red top-left corner shows where the image ends up after rotation.
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			img.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+	return img
+}
+
+// exifSegment builds an APP1 segment holding a little-endian TIFF header
+// with a GPS-looking IFD and the given orientation.
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS 51.5N 0.1W"...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	seg := []byte{0xFF, 0xE1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+func jpegWithExif(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, exifSegment(orientation)...)
+	out = append(out, 0xFF, 0xFE, 0x00, 0x08)
+	out = append(out, "camera"...)
+	return append(out, data[2:]...)
+}
+
+func TestProcess_JPEG(t *testing.T) {
+	data := jpegWithExif(t, testImage(2000, 1000), 1)
+
+	result, err := Process(data, "image/jpeg", Sizes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if bytes.Contains(result.Original, []byte("Exif")) || bytes.Contains(result.Original, []byte("GPS")) || bytes.Contains(result.Original, []byte("camera")) {
+		t.Error("Expected metadata removed from the original")
+	}
+
+	if _, err := jpeg.Decode(bytes.NewReader(result.Original)); err != nil {
+		t.Errorf("Expected a valid JPEG, got %v", err)
+	}
+
+	if result.Width != 2000 || result.Height != 1000 || len(result.Variants) != 3 {
+		t.Fatalf("Unexpected result %dx%d with %d variants", result.Width, result.Height, len(result.Variants))
+	}
+
+	want := []struct {
+		name          string
+		width, height int
+	}{{"thumbnail", 320, 160}, {"medium", 800, 400}, {"large", 1600, 800}}
+	for i, w := range want {
+		v := result.Variants[i]
+		if v.Name != w.name || v.Width != w.width || v.Height != w.height || v.ContentType != "image/jpeg" {
+			t.Errorf("Expected %s %dx%d, got %s %dx%d %s", w.name, w.width, w.height, v.Name, v.Width, v.Height, v.ContentType)
+		}
+
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(v.Data))
+		if err != nil || cfg.Width != w.width {
+			t.Errorf("Expected %s to decode at %dpx, got %+v %v", w.name, w.width, cfg, err)
+		}
+	}
+}
+
+func TestProcess_Orientation(t *testing.T) {
+	data := jpegWithExif(t, testImage(1000, 600), 6)
+
+	result, err := Process(data, "image/jpeg", Sizes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Width != 600 || result.Height != 1000 {
+		t.Errorf("Expected the original turned upright, got %dx%d", result.Width, result.Height)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(result.Original))
+	if err != nil {
+		t.Fatalf("Expected a valid JPEG, got %v", err)
+	}
+
+	// Rotating clockwise moves the top-left corner to the top right.
+	if r, g, _, _ := img.At(595, 4).RGBA(); r>>8 < 200 || g>>8 > 60 {
+		t.Errorf("Expected the red corner at the top right, got %v", img.At(595, 4))
+	}
+
+	if len(result.Variants) != 1 || result.Variants[0].Width != 320 || result.Variants[0].Height != 533 {
+		t.Errorf("Expected only variants narrower than the original, got %d", len(result.Variants))
+	}
+}
+
+func TestOrient(t *testing.T) {
+	src := image.NewRGBA(image.Rect(10, 20, 13, 22))
+	for i := range src.Pix {
+		src.Pix[i] = byte(i)
+	}
+	w, h := 3, 2
+
+	// source returns the pixel of src that lands on (x, y) of the upright image.
+	source := map[int]func(x, y int) (int, int){
+		1: func(x, y int) (int, int) { return x, y },
+		2: func(x, y int) (int, int) { return w - 1 - x, y },
+		3: func(x, y int) (int, int) { return w - 1 - x, h - 1 - y },
+		4: func(x, y int) (int, int) { return x, h - 1 - y },
+		5: func(x, y int) (int, int) { return y, x },
+		6: func(x, y int) (int, int) { return y, h - 1 - x },
+		7: func(x, y int) (int, int) { return w - 1 - y, h - 1 - x },
+		8: func(x, y int) (int, int) { return w - 1 - y, x },
+	}
+
+	for orientation := 1; orientation <= 8; orientation++ {
+		got := orient(src, orientation)
+		b := got.Bounds()
+		dw, dh := w, h
+		if orientation >= 5 {
+			dw, dh = h, w
+		}
+		if b.Dx() != dw || b.Dy() != dh {
+			t.Errorf("orientation %d: expected %dx%d, got %v", orientation, dw, dh, b)
+			continue
+		}
+
+		for y := 0; y < dh; y++ {
+			for x := 0; x < dw; x++ {
+				sx, sy := source[orientation](x, y)
+				want := src.At(src.Rect.Min.X+sx, src.Rect.Min.Y+sy)
+				if c := got.At(b.Min.X+x, b.Min.Y+y); c != want {
+					t.Errorf("orientation %d: pixel (%d, %d) is %v, expected %v", orientation, x, y, c, want)
+				}
+			}
+		}
+	}
+}
+
+func pngChunk(kind string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestProcess_PNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(400, 200)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// Insert a tEXt chunk right after IHDR (8 byte signature + 25 byte chunk).
+	chunk := pngChunk("tEXt", []byte("Author\x00someone"))
+	data = append(append(append([]byte{}, data[:33]...), chunk...), data[33:]...)
+
+	result, err := Process(data, "image/png", Sizes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if bytes.Contains(result.Original, []byte("someone")) {
+		t.Error("Expected text chunks removed")
+	}
+
+	if _, err := png.Decode(bytes.NewReader(result.Original)); err != nil {
+		t.Errorf("Expected a valid PNG, got %v", err)
+	}
+
+	if len(result.Variants) != 1 || result.Variants[0].ContentType != "image/png" || result.Variants[0].Width != 320 {
+		t.Errorf("Expected a PNG thumbnail, got %d variants", len(result.Variants))
+	}
+}
+
+func TestProcess_Invalid(t *testing.T) {
+	if _, err := Process([]byte("\xFF\xD8 not really"), "image/jpeg", Sizes); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid, got %v", err)
+	}
+
+	// A valid header claiming an enormous image.
+	ihdr := binary.BigEndian.AppendUint32(nil, 100000)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, 100000)
+	ihdr = append(ihdr, 8, 2, 0, 0, 0)
+	header := append([]byte("\x89PNG\r\n\x1a\n"), pngChunk("IHDR", ihdr)...)
+	if _, err := Process(header, "image/png", Sizes); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestStripWebP(t *testing.T) {
+	chunk := func(fourCC string, data []byte) []byte {
+		out := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+		out = append(out, data...)
+		if len(data)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", []byte{0x0C, 0, 0, 0, 0, 0, 0, 0, 0, 0})...)
+	body = append(body, chunk("VP8 ", []byte("image"))...)
+	body = append(body, chunk("EXIF", []byte("GPS"))...)
+	body = append(body, chunk("XMP ", []byte("<x/>"))...)
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+
+	out, err := stripWebP(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if bytes.Contains(out, []byte("GPS")) || bytes.Contains(out, []byte("<x/>")) {
+		t.Error("Expected metadata chunks removed")
+	}
+
+	if flags := out[20]; flags != 0 {
+		t.Errorf("Expected metadata flags cleared, got %#x", flags)
+	}
+
+	if size := binary.LittleEndian.Uint32(out[4:]); int(size) != len(out)-8 {
+		t.Errorf("Expected RIFF size %d, got %d", len(out)-8, size)
+	}
+}
+
+func TestStripGIF(t *testing.T) {
+	frame := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black, color.White})
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, &gif.GIF{Image: []*image.Paletted{frame, frame}, Delay: []int{10, 10}}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// Extensions may come between any two blocks; add them before the trailer.
+	comment := []byte{0x21, 0xFE, 7, 's', 'o', 'm', 'e', 'o', 'n', 'e', 0}
+	xmp := append([]byte{0x21, 0xFF, 11}, "XMP DataXMP"...)
+	xmp = append(xmp, 4, '<', 'x', '/', '>', 0)
+	data = append(append(append(data[:len(data)-1:len(data)-1], comment...), xmp...), 0x3B)
+
+	out, err := stripGIF(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if bytes.Contains(out, []byte("someone")) || bytes.Contains(out, []byte("<x/>")) {
+		t.Error("Expected comments and XMP removed")
+	}
+	if !bytes.Contains(out, []byte("NETSCAPE2.0")) {
+		t.Error("Expected the looping extension kept")
+	}
+
+	decoded, err := gif.DecodeAll(bytes.NewReader(out))
+	if err != nil || len(decoded.Image) != 2 {
+		t.Errorf("Expected a valid GIF with 2 frames, got %v", err)
+	}
+}
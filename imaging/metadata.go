@@ -0,0 +1,300 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// JPEG segments kept when stripping: JFIF (APP0), ICC colour profiles
+// (APP2) and Adobe's colour transform flag (APP14). The other application
+// segments carry EXIF, XMP, IPTC and vendor data; comments go as well.
+var keptJPEGSegments = map[byte]bool{0xE0: true, 0xE2: true, 0xEE: true}
+
+// stripJPEG removes metadata segments without touching the compressed
+// image data.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, fmt.Errorf("%w: missing JPEG header", ErrInvalid)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return nil, fmt.Errorf("%w: malformed JPEG segment", ErrInvalid)
+		}
+		marker := data[i+1]
+
+		// The scan runs to the end of the image; everything from here on
+		// is compressed data.
+		if marker == 0xDA {
+			return append(out, data[i:]...), nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, fmt.Errorf("%w: truncated JPEG segment", ErrInvalid)
+		}
+
+		drop := marker == 0xFE || (marker >= 0xE1 && marker <= 0xEF && !keptJPEGSegments[marker])
+		if !drop {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return nil, fmt.Errorf("%w: JPEG without image data", ErrInvalid)
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Textual chunks, EXIF and timestamps are dropped from PNGs; chunks carry
+// their own checksums, so the rest can be copied as they are.
+var droppedPNGChunks = map[string]bool{"tEXt": true, "zTXt": true, "iTXt": true, "eXIf": true, "tIME": true}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("%w: missing PNG signature", ErrInvalid)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	i := len(pngSignature)
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, fmt.Errorf("%w: truncated PNG chunk", ErrInvalid)
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, fmt.Errorf("%w: truncated PNG chunk", ErrInvalid)
+		}
+
+		if !droppedPNGChunks[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// stripWebP drops the EXIF and XMP chunks of a WebP file and clears the
+// flags announcing them in its extended header.
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, fmt.Errorf("%w: missing WebP header", ErrInvalid)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	i := 12
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, fmt.Errorf("%w: truncated WebP chunk", ErrInvalid)
+		}
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if size < 0 || end > len(data) {
+			return nil, fmt.Errorf("%w: truncated WebP chunk", ErrInvalid)
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[i:end]...)
+			if size > 0 {
+				out[start+8] &^= 0x08 | 0x04
+			}
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// GIF application extensions kept when stripping: the ones that make
+// animations loop and ICC colour profiles. Others carry XMP and vendor
+// data; comments go as well.
+var keptGIFApplications = map[string]bool{"NETSCAPE2.0": true, "ANIMEXTS1.0": true, "ICCRGBG1012": true}
+
+// stripGIF drops comment and application extensions from a GIF, copying
+// frames and their graphic control as they are.
+func stripGIF(data []byte) ([]byte, error) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return nil, fmt.Errorf("%w: missing GIF header", ErrInvalid)
+	}
+
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1)
+	}
+	if i > len(data) {
+		return nil, fmt.Errorf("%w: truncated GIF header", ErrInvalid)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:i]...)
+	for i < len(data) {
+		start := i
+		switch data[i] {
+		case 0x3B:
+			return append(out, data[i]), nil
+		case 0x21:
+			if i+2 > len(data) {
+				return nil, fmt.Errorf("%w: truncated GIF extension", ErrInvalid)
+			}
+			label := data[i+1]
+			end, ok := gifSubBlocks(data, i+2)
+			if !ok {
+				return nil, fmt.Errorf("%w: truncated GIF extension", ErrInvalid)
+			}
+			i = end
+
+			drop := label == 0xFE
+			if label == 0xFF {
+				drop = start+14 > end || data[start+2] != 11 || !keptGIFApplications[string(data[start+3:start+14])]
+			}
+			if drop {
+				continue
+			}
+		case 0x2C:
+			if i+11 > len(data) {
+				return nil, fmt.Errorf("%w: truncated GIF frame", ErrInvalid)
+			}
+			i += 10
+			if data[start+9]&0x80 != 0 {
+				i += 3 << (data[start+9]&0x07 + 1)
+			}
+			// The LZW minimum code size comes before the image data.
+			end, ok := gifSubBlocks(data, i+1)
+			if !ok {
+				return nil, fmt.Errorf("%w: truncated GIF frame", ErrInvalid)
+			}
+			i = end
+		default:
+			return nil, fmt.Errorf("%w: malformed GIF block", ErrInvalid)
+		}
+		out = append(out, data[start:i]...)
+	}
+	return nil, fmt.Errorf("%w: GIF without trailer", ErrInvalid)
+}
+
+// gifSubBlocks returns where the sub-blocks starting at i end, past their
+// terminator.
+func gifSubBlocks(data []byte, i int) (int, bool) {
+	for i < len(data) {
+		size := int(data[i])
+		i++
+		if size == 0 {
+			return i, true
+		}
+		i += size
+	}
+	return 0, false
+}
+
+// exifOrientation reads the orientation tag from a JPEG's EXIF segment,
+// returning 1 (upright) when there is none or it cannot be read.
+func exifOrientation(data []byte) int {
+	i := 2
+	for i+4 <= len(data) && data[i] == 0xFF && data[i+1] != 0xDA {
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+		if data[i+1] == 0xE1 && bytes.HasPrefix(data[i+4:end], []byte("Exif\x00\x00")) {
+			return tiffOrientation(data[i+10 : end])
+		}
+		i = end
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orientTransforms map the pixels of a w×h image onto its upright
+// version, as the coefficients of x and y and the multiples of w and h
+// added to each coordinate. Orientations 5 to 8 swap width and height.
+var orientTransforms = map[int][2][4]float64{
+	2: {{-1, 0, 1, 0}, {0, 1, 0, 0}},
+	3: {{-1, 0, 1, 0}, {0, -1, 0, 1}},
+	4: {{1, 0, 0, 0}, {0, -1, 0, 1}},
+	5: {{0, 1, 0, 0}, {1, 0, 0, 0}},
+	6: {{0, -1, 0, 1}, {1, 0, 0, 0}},
+	7: {{0, -1, 0, 1}, {-1, 0, 1, 0}},
+	8: {{0, 1, 0, 0}, {-1, 0, 1, 0}},
+}
+
+// orient applies an EXIF orientation so the image is stored upright. Pixels
+// are copied straight from the decoded image into the rotated one, so only
+// one extra copy of the image is ever held.
+func orient(img image.Image, orientation int) image.Image {
+	t, ok := orientTransforms[orientation]
+	if !ok {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	var m f64.Aff3
+	for row, c := range t {
+		x, y := c[0], c[1]
+		m[row*3], m[row*3+1] = x, y
+		m[row*3+2] = c[2]*float64(w) + c[3]*float64(h) - x*float64(b.Min.X) - y*float64(b.Min.Y)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	draw.NearestNeighbor.Transform(dst, m, img, b, draw.Src, nil)
+	return dst
+}
@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo, "json").With("component", "test")
+
+	logger.DebugContext(context.Background(), "hidden")
+	logger.InfoContext(WithRequestID(context.Background(), "abc123"), "visible", "id", 7)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a single JSON record, got %q: %v", buf.String(), err)
+	}
+	if record["msg"] != "visible" || record["request_id"] != "abc123" || record["component"] != "test" {
+		t.Errorf("Unexpected record %v", record)
+	}
+}
+
+func TestOrDiscard(t *testing.T) {
+	OrDiscard(nil).Error("dropped")
+
+	logger := slog.Default()
+	if OrDiscard(logger) != logger {
+		t.Error("Expected the given logger back")
+	}
+}
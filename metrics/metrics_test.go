@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T, reg *Registry) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	reg.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Expected the text exposition format, got %q", ct)
+	}
+	return w.Body.String()
+}
+
+func TestCounter(t *testing.T) {
+	reg := NewRegistry()
+	plain := reg.NewCounter("logins_failed_total", "Failed logins.")
+	requests := reg.NewCounter("requests_total", "Requests\nserved.", "route", "status")
+
+	requests.Inc("GET /entries", "200")
+	requests.Inc("GET /entries", "200")
+	requests.Add(3, `GET /say/"hi"`, "404")
+
+	got := scrape(t, reg)
+	want := `# HELP logins_failed_total Failed logins.
+# TYPE logins_failed_total counter
+logins_failed_total 0
+# HELP requests_total Requests\nserved.
+# TYPE requests_total counter
+requests_total{route="GET /entries",status="200"} 2
+requests_total{route="GET /say/\"hi\"",status="404"} 3
+`
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	plain.Inc()
+	if !strings.Contains(scrape(t, reg), "logins_failed_total 1\n") {
+		t.Error("Expected the unlabelled counter to count")
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	reg := NewRegistry()
+	h := reg.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+
+	h.Observe(0.05, "a")
+	h.Observe(0.1, "a")
+	h.Observe(0.5, "a")
+	h.Observe(3, "a")
+
+	got := scrape(t, reg)
+	want := `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="a",le="0.1"} 2
+latency_seconds_bucket{route="a",le="1"} 3
+latency_seconds_bucket{route="a",le="+Inf"} 4
+latency_seconds_sum{route="a"} 3.65
+latency_seconds_count{route="a"} 4
+`
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestFuncMetrics(t *testing.T) {
+	reg := NewRegistry()
+	var g Gauge
+	reg.NewGaugeFunc("in_flight", "In flight.", g.Value)
+	reg.NewCounterFunc("acquires_total", "Acquires.", func() float64 { return 42 })
+
+	g.Add(2)
+	g.Add(-1)
+
+	got := scrape(t, reg)
+	for _, want := range []string{"# TYPE in_flight gauge\nin_flight 1\n", "# TYPE acquires_total counter\nacquires_total 42\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestRegistryRejectsDuplicates(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("dup_total", "")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+	reg.NewCounter("dup_total", "")
+}
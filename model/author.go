@@ -1,7 +1,25 @@
 package model
 
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleWriter Role = "writer"
+	RoleReader Role = "reader"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleEditor, RoleWriter, RoleReader:
+		return true
+	}
+	return false
+}
+
 type Author struct {
 	Username string `json:"username"`
-	Email string `json:"email"`
-	Password string `json:"password"`
-}
\ No newline at end of file
+	Email    string `json:"email"`
+	Password string `json:"-"`
+	Role     Role   `json:"role"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+type CommentStatus string
+
+const (
+	CommentPending  CommentStatus = "pending"
+	CommentApproved CommentStatus = "approved"
+	CommentSpam     CommentStatus = "spam"
+)
+
+func (s CommentStatus) Valid() bool {
+	switch s {
+	case CommentPending, CommentApproved, CommentSpam:
+		return true
+	}
+	return false
+}
+
+// Comment is a reader's response to an entry. Replies point at the comment
+// they answer through ParentID and are nested under it in Replies when a
+// thread is read. AuthorEmail is only shown to moderators.
+type Comment struct {
+	ID          int           `json:"id"`
+	EntryID     int           `json:"entry_id"`
+	ParentID    *int          `json:"parent_id,omitempty"`
+	AuthorName  string        `json:"author_name"`
+	AuthorEmail string        `json:"author_email,omitempty"`
+	Body        string        `json:"body"`
+	Status      CommentStatus `json:"status"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Replies     []Comment     `json:"replies,omitempty"`
+}
+
+// CommentQuery selects comments for the moderation queue, oldest first.
+type CommentQuery struct {
+	Status CommentStatus
+	Limit  int
+	Offset int
+}
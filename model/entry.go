@@ -2,11 +2,62 @@ package model
 
 import "time"
 
+type EntryStatus string
+
+const (
+	StatusDraft     EntryStatus = "draft"
+	StatusPublished EntryStatus = "published"
+	StatusScheduled EntryStatus = "scheduled"
+	StatusArchived  EntryStatus = "archived"
+)
+
+func (s EntryStatus) Valid() bool {
+	switch s {
+	case StatusDraft, StatusPublished, StatusScheduled, StatusArchived:
+		return true
+	}
+	return false
+}
+
+// BodyFormat tells how an entry's body is written and therefore how it is
+// turned into HTML.
+type BodyFormat string
+
+const (
+	FormatMarkdown BodyFormat = "markdown"
+	FormatHTML     BodyFormat = "html"
+	FormatPlain    BodyFormat = "plain"
+)
+
+func (f BodyFormat) Valid() bool {
+	switch f {
+	case FormatMarkdown, FormatHTML, FormatPlain:
+		return true
+	}
+	return false
+}
+
+// Entry holds the body as written alongside its rendering: sanitized HTML,
+// a plain-text excerpt and an estimated reading time in minutes. The
+// rendered fields are derived from Body and BodyFormat on every write.
 type Entry struct {
-    ID int `json:"id"`
-    Title string `json:"title"`
-    Slug string `json:"slug"`
-    Body string `json:"body"`
-    Author string `json:"author"`
-    CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+	ID          int         `json:"id"`
+	Title       string      `json:"title"`
+	Slug        string      `json:"slug"`
+	Body        string      `json:"body"`
+	BodyFormat  BodyFormat  `json:"body_format"`
+	BodyHTML    string      `json:"body_html"`
+	Excerpt     string      `json:"excerpt"`
+	ReadingTime int         `json:"reading_time"`
+	Author      string      `json:"author"`
+	Tags        []string    `json:"tags"`
+	Status      EntryStatus `json:"status"`
+	PublishedAt *time.Time  `json:"published_at,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// IsPublic reports whether the entry may be shown to anonymous readers.
+func (e Entry) IsPublic(now time.Time) bool {
+	return e.Status == StatusPublished && e.PublishedAt != nil && !e.PublishedAt.After(now)
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+type EntrySort string
+
+const (
+	SortByCreatedAt   EntrySort = "created_at"
+	SortByPublishedAt EntrySort = "published_at"
+	SortByTitle       EntrySort = "title"
+)
+
+type SortOrder string
+
+const (
+	Ascending  SortOrder = "asc"
+	Descending SortOrder = "desc"
+)
+
+type EntryQuery struct {
+	Limit    int
+	Offset   int
+	Cursor   string
+	Sort     EntrySort
+	Order    SortOrder
+	Author   string
+	Status   EntryStatus
+	Tags     []string
+	TagMatch TagMatch
+	From     *time.Time
+	To       *time.Time
+}
+
+type EntryPage struct {
+	Entries    []Entry
+	Total      int
+	Limit      int
+	Offset     int
+	NextCursor string
+}
+
+type SearchQuery struct {
+	Text   string
+	Limit  int
+	Offset int
+}
+
+type SearchResult struct {
+	Entry   Entry   `json:"entry"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Media is an uploaded file. The file itself lives in storage under Key;
+// Checksum is the SHA-256 of its contents in hex. Images also carry their
+// dimensions and smaller variants for responsive layouts, with SrcSet
+// listing the original and every variant in the format of the HTML srcset
+// attribute.
+type Media struct {
+	ID          int            `json:"id"`
+	Owner       string         `json:"owner"`
+	Filename    string         `json:"filename"`
+	ContentType string         `json:"content_type"`
+	Size        int64          `json:"size"`
+	Checksum    string         `json:"checksum"`
+	Width       int            `json:"width,omitempty"`
+	Height      int            `json:"height,omitempty"`
+	Key         string         `json:"-"`
+	URL         string         `json:"url"`
+	SrcSet      string         `json:"srcset,omitempty"`
+	Variants    []MediaVariant `json:"variants"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// MediaVariant is a resized copy of an image, named after its size such as
+// "thumbnail" or "large".
+type MediaVariant struct {
+	Name        string `json:"name"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Key         string `json:"-"`
+	URL         string `json:"url"`
+}
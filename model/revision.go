@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Revision is a snapshot of an entry's content as it was before an update.
+// Numbers start at 1 and grow with every update of the same entry.
+type Revision struct {
+	EntryID    int        `json:"entry_id"`
+	Number     int        `json:"revision"`
+	Title      string     `json:"title"`
+	Slug       string     `json:"slug"`
+	Body       string     `json:"body"`
+	BodyFormat BodyFormat `json:"body_format"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// RevisionDiff compares two versions of an entry line by line. A revision
+// number of 0 stands for the entry as it currently is.
+type RevisionDiff struct {
+	EntryID int        `json:"entry_id"`
+	From    int        `json:"from"`
+	To      int        `json:"to"`
+	Title   []DiffLine `json:"title"`
+	Slug    []DiffLine `json:"slug"`
+	Body    []DiffLine `json:"body"`
+}
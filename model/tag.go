@@ -0,0 +1,17 @@
+package model
+
+// Tag groups entries. Names are stored normalized, in the same URL-safe form
+// as slugs, so they can be used directly in links and filters.
+type Tag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// TagMatch decides whether an entry listing filtered by several tags wants
+// entries carrying all of them or any of them.
+type TagMatch string
+
+const (
+	MatchAllTags TagMatch = "all"
+	MatchAnyTag  TagMatch = "any"
+)
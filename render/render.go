@@ -0,0 +1,120 @@
+// Package render turns entry bodies into sanitized HTML, plain-text excerpts
+// and reading-time estimates.
+package render
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/juanplagos/bubble/model"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+const (
+	// ExcerptLength is the maximum length of an excerpt in characters.
+	ExcerptLength = 200
+	// WordsPerMinute is the reading speed reading times are estimated with.
+	WordsPerMinute = 200
+)
+
+// Result is what an entry body renders to.
+type Result struct {
+	HTML        string
+	Excerpt     string
+	ReadingTime int
+}
+
+// Raw HTML is let through goldmark on purpose: everything it outputs goes
+// through the sanitizer, which is the single place deciding what is allowed.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+)
+
+// sanitizer allows the usual formatting, links and images, but no scripts,
+// styles, forms, frames or event handlers.
+var sanitizer = bluemonday.UGCPolicy()
+
+var stripTags = bluemonday.StrictPolicy()
+
+// blockTag matches the tags that separate words when markup is stripped.
+var blockTag = regexp.MustCompile(`(?i)<(?:/?(?:p|h[1-6]|li|ul|ol|dl|dt|dd|div|blockquote|pre|table|tr|td|th|figure|figcaption)|br|hr|img)\b`)
+
+// Body renders body according to its format. The HTML is always sanitized,
+// whatever the format.
+func Body(format model.BodyFormat, body string) (Result, error) {
+	var unsafe string
+	switch format {
+	case model.FormatMarkdown:
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(body), &buf); err != nil {
+			return Result{}, err
+		}
+		unsafe = buf.String()
+	case model.FormatPlain:
+		unsafe = plainToHTML(body)
+	default:
+		unsafe = body
+	}
+
+	safe := sanitizer.Sanitize(unsafe)
+	text := plainText(safe)
+	return Result{
+		HTML:        safe,
+		Excerpt:     excerpt(text, ExcerptLength),
+		ReadingTime: readingTime(text),
+	}, nil
+}
+
+// plainToHTML escapes text and turns blank-line separated blocks into
+// paragraphs, keeping single line breaks.
+func plainToHTML(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var b strings.Builder
+	for _, block := range strings.Split(text, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(block), "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+// plainText strips the markup from sanitized HTML, separating block
+// elements so words on either side of them do not run together.
+func plainText(safeHTML string) string {
+	text := stripTags.Sanitize(blockTag.ReplaceAllString(safeHTML, " $0"))
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+// excerpt shortens text to at most max characters, cutting at a word
+// boundary and marking the cut with an ellipsis.
+func excerpt(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:max-1])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
+
+// readingTime estimates minutes to read text, rounding up so that any
+// non-empty text takes at least a minute.
+func readingTime(text string) int {
+	words := len(strings.Fields(text))
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
@@ -0,0 +1,100 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+func TestBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  model.BodyFormat
+		body    string
+		html    string
+		excerpt string
+	}{
+		{
+			name:    "markdown",
+			format:  model.FormatMarkdown,
+			body:    "# Título\n\nUm *parágrafo* com [link](https://example.com).",
+			html:    "<h1>Título</h1>\n<p>Um <em>parágrafo</em> com <a href=\"https://example.com\" rel=\"nofollow\">link</a>.</p>\n",
+			excerpt: "Título Um parágrafo com link.",
+		},
+		{
+			name:    "markdown with raw html",
+			format:  model.FormatMarkdown,
+			body:    "Olá <script>alert(1)</script><b onclick=\"x()\">mundo</b>",
+			html:    "<p>Olá <b>mundo</b></p>\n",
+			excerpt: "Olá mundo",
+		},
+		{
+			name:    "html",
+			format:  model.FormatHTML,
+			body:    `<p style="color:red">Oi</p><iframe src="https://evil.example"></iframe><a href="javascript:alert(1)">x</a>`,
+			html:    "<p>Oi</p>x",
+			excerpt: "Oi x",
+		},
+		{
+			name:    "plain",
+			format:  model.FormatPlain,
+			body:    "Linha 1\nLinha <2>\n\nOutro & parágrafo",
+			html:    "<p>Linha 1<br>\nLinha &lt;2&gt;</p>\n<p>Outro &amp; parágrafo</p>\n",
+			excerpt: "Linha 1 Linha <2> Outro & parágrafo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Body(tt.format, tt.body)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if result.HTML != tt.html {
+				t.Errorf("Expected HTML %q, got %q", tt.html, result.HTML)
+			}
+
+			if result.Excerpt != tt.excerpt {
+				t.Errorf("Expected excerpt %q, got %q", tt.excerpt, result.Excerpt)
+			}
+
+			if result.ReadingTime != 1 {
+				t.Errorf("Expected reading time 1, got %d", result.ReadingTime)
+			}
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	text := strings.Repeat("palavra ", 100)
+
+	got := excerpt(text, ExcerptLength)
+
+	if n := len([]rune(got)); n > ExcerptLength {
+		t.Errorf("Expected at most %d characters, got %d", ExcerptLength, n)
+	}
+
+	if !strings.HasSuffix(got, "palavra…") {
+		t.Errorf("Expected cut at a word boundary, got %q", got)
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words int
+		want  int
+	}{
+		{0, 0},
+		{1, 1},
+		{WordsPerMinute, 1},
+		{WordsPerMinute + 1, 2},
+	}
+
+	for _, tt := range tests {
+		if got := readingTime(strings.Repeat("a ", tt.words)); got != tt.want {
+			t.Errorf("readingTime(%d words) = %d, want %d", tt.words, got, tt.want)
+		}
+	}
+}
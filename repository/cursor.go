@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+// entryCursor marks the last row of a page so the next one can resume with a
+// keyset comparison instead of an ever-growing OFFSET.
+type entryCursor struct {
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func encodeEntryCursor(sort model.EntrySort, e model.Entry) string {
+	c := entryCursor{ID: e.ID, Value: e.Title}
+	switch sort {
+	case model.SortByCreatedAt:
+		c.Value = e.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case model.SortByPublishedAt:
+		if e.PublishedAt != nil {
+			c.Value = e.PublishedAt.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeEntryCursor(sort model.EntrySort, cursor string) (any, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: malformed cursor", ErrInvalid)
+	}
+
+	var c entryCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, 0, fmt.Errorf("%w: malformed cursor", ErrInvalid)
+	}
+
+	if sort == model.SortByCreatedAt || sort == model.SortByPublishedAt {
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: malformed cursor", ErrInvalid)
+		}
+		return t, c.ID, nil
+	}
+	return c.Value, c.ID, nil
+}
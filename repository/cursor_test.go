@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+func TestEntryCursor(t *testing.T) {
+	entry := model.Entry{ID: 42, Title: "Olá mundo", CreatedAt: time.Date(2025, 3, 1, 12, 30, 0, 123456000, time.UTC)}
+
+	t.Run("created_at", func(t *testing.T) {
+		cursor := encodeEntryCursor(model.SortByCreatedAt, entry)
+
+		value, id, err := decodeEntryCursor(model.SortByCreatedAt, cursor)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if id != 42 || !value.(time.Time).Equal(entry.CreatedAt) {
+			t.Errorf("Expected (%v, 42), got (%v, %d)", entry.CreatedAt, value, id)
+		}
+	})
+
+	t.Run("published_at", func(t *testing.T) {
+		published := entry.CreatedAt.Add(time.Hour)
+		entry := entry
+		entry.PublishedAt = &published
+
+		cursor := encodeEntryCursor(model.SortByPublishedAt, entry)
+
+		value, id, err := decodeEntryCursor(model.SortByPublishedAt, cursor)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if id != 42 || !value.(time.Time).Equal(published) {
+			t.Errorf("Expected (%v, 42), got (%v, %d)", published, value, id)
+		}
+	})
+
+	t.Run("title", func(t *testing.T) {
+		cursor := encodeEntryCursor(model.SortByTitle, entry)
+
+		value, id, err := decodeEntryCursor(model.SortByTitle, cursor)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if id != 42 || value != "Olá mundo" {
+			t.Errorf("Expected (Olá mundo, 42), got (%v, %d)", value, id)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		if _, _, err := decodeEntryCursor(model.SortByTitle, "%%%"); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Expected ErrInvalid, got %v", err)
+		}
+	})
+
+	t.Run("sort mismatch", func(t *testing.T) {
+		cursor := encodeEntryCursor(model.SortByTitle, entry)
+
+		if _, _, err := decodeEntryCursor(model.SortByCreatedAt, cursor); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Expected ErrInvalid, got %v", err)
+		}
+	})
+}
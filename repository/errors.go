@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrInvalid     = errors.New("invalid")
+	ErrUnavailable = errors.New("database unavailable")
+)
+
+// translateError maps pgx errors onto the sentinels above so callers never
+// have to know about SQL states or driver error types. A query cut short
+// by its context reports the context's error instead, so callers can tell
+// a deadline or a departed client from an unhealthy database.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return context.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return context.Canceled
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23505":
+			return fmt.Errorf("%w: %s already exists", ErrConflict, conflictingField(pgErr))
+		case pgErr.Code == "23503":
+			return fmt.Errorf("%w: referenced by other records", ErrConflict)
+		case strings.HasPrefix(pgErr.Code, "22"), strings.HasPrefix(pgErr.Code, "23"):
+			if pgErr.ColumnName != "" {
+				return fmt.Errorf("%w: %s", ErrInvalid, pgErr.ColumnName)
+			}
+			return ErrInvalid
+		case strings.HasPrefix(pgErr.Code, "08"), strings.HasPrefix(pgErr.Code, "53"), strings.HasPrefix(pgErr.Code, "57P"):
+			return ErrUnavailable
+		}
+		return err
+	}
+
+	var connErr *pgconn.ConnectError
+	var netErr net.Error
+	if errors.As(err, &connErr) || errors.As(err, &netErr) || pgconn.Timeout(err) {
+		return ErrUnavailable
+	}
+
+	return err
+}
+
+// conflictingField extracts the column list from a unique violation detail
+// such as "Key (slug)=(hello) already exists.".
+func conflictingField(pgErr *pgconn.PgError) string {
+	detail, ok := strings.CutPrefix(pgErr.Detail, "Key (")
+	if !ok {
+		return "record"
+	}
+	field, _, ok := strings.Cut(detail, ")=")
+	if !ok {
+		return "record"
+	}
+	return field
+}
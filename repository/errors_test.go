@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"no rows", pgx.ErrNoRows, ErrNotFound},
+		{"wrapped no rows", fmt.Errorf("query: %w", pgx.ErrNoRows), ErrNotFound},
+		{"unique violation", &pgconn.PgError{Code: "23505", Detail: "Key (slug)=(hello) already exists."}, ErrConflict},
+		{"foreign key violation", &pgconn.PgError{Code: "23503"}, ErrConflict},
+		{"not null violation", &pgconn.PgError{Code: "23502"}, ErrInvalid},
+		{"value too long", &pgconn.PgError{Code: "22001"}, ErrInvalid},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, ErrUnavailable},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, ErrUnavailable},
+		{"deadline", fmt.Errorf("timeout: %w", context.DeadlineExceeded), context.DeadlineExceeded},
+		{"canceled", fmt.Errorf("write failed: %w", context.Canceled), context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translateError(tt.err); !errors.Is(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		if got := translateError(nil); got != nil {
+			t.Errorf("Expected nil, got %v", got)
+		}
+	})
+
+	t.Run("unknown error passes through", func(t *testing.T) {
+		err := errors.New("boom")
+		if got := translateError(err); got != err {
+			t.Errorf("Expected original error, got %v", got)
+		}
+	})
+
+	t.Run("conflict names the field", func(t *testing.T) {
+		err := translateError(&pgconn.PgError{Code: "23505", Detail: "Key (username)=(juan) already exists."})
+		if err.Error() != "conflict: username already exists" {
+			t.Errorf("Expected field in message, got %q", err.Error())
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/model"
+)
+
+// errReferenced is what a foreign key violation translates to.
+var errReferenced = fmt.Errorf("%w: referenced by other records", ErrConflict)
+
+// MemoryStore holds the data of the in-memory repositories for demos and
+// end-to-end tests. They share one lock, so references between entries and
+// authors are enforced the way the foreign keys in Postgres enforce them,
+// and report the same errors translateError would.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	now         func() time.Time
+	authors     map[string]model.Author
+	entries     map[int]model.Entry
+	lastEntryID int
+	revisions   map[int][]model.Revision
+	oldSlugs    map[string]int
+	logger      *slog.Logger
+}
+
+func NewMemoryStore(logger *slog.Logger) *MemoryStore {
+	return &MemoryStore{
+		logger: logging.OrDiscard(logger),
+		// Postgres keeps timestamps to the microsecond.
+		now:       func() time.Time { return time.Now().Truncate(time.Microsecond) },
+		authors:   make(map[string]model.Author),
+		entries:   make(map[int]model.Entry),
+		revisions: make(map[int][]model.Revision),
+		oldSlugs:  make(map[string]int),
+	}
+}
+
+// refuse logs a write to table that broke one of its constraints and
+// returns err. It is logged at debug level, like the query tracer logs the
+// errors Postgres reports for the same writes.
+func (s *MemoryStore) refuse(ctx context.Context, table string, err error) error {
+	s.logger.DebugContext(ctx, "escrita recusada", "table", table, "error", err)
+	return err
+}
+
+// NewMemoryRepos backs entries, authors and tags with store. Comments and
+// media only exist in Postgres; their repositories report ErrUnavailable.
+func NewMemoryRepos(store *MemoryStore) Repos {
+	return Repos{
+		Entries:  NewMemoryEntryRepo(store),
+		Authors:  NewMemoryAuthorRepo(store),
+		Tags:     NewMemoryTagRepo(store),
+		Comments: unavailableRepo{},
+		Media:    unavailableRepo{},
+	}
+}
+
+type MemoryTagRepo struct {
+	store *MemoryStore
+}
+
+func NewMemoryTagRepo(store *MemoryStore) *MemoryTagRepo {
+	return &MemoryTagRepo{
+		store: store,
+	}
+}
+
+// GetAllTags lists the tags in use by published entries, most used first.
+func (repo *MemoryTagRepo) GetAllTags(ctx context.Context) ([]model.Tag, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	now := s.now()
+	for _, e := range s.entries {
+		if !e.IsPublic(now) {
+			continue
+		}
+		for _, name := range e.Tags {
+			counts[name]++
+		}
+	}
+
+	tags := []model.Tag{}
+	for name, count := range counts {
+		tags = append(tags, model.Tag{Name: name, Count: count})
+	}
+	slices.SortFunc(tags, func(a, b model.Tag) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Name, b.Name))
+	})
+	return tags, nil
+}
+
+// unavailableRepo stands in for the repositories the in-memory store does
+// not implement, so their routes answer 503 instead of failing on a
+// missing pool.
+type unavailableRepo struct{}
+
+func (unavailableRepo) GetEntryComments(ctx context.Context, entryID int, status model.CommentStatus) ([]model.Comment, error) {
+	return nil, ErrUnavailable
+}
+
+func (unavailableRepo) GetComments(ctx context.Context, query model.CommentQuery) ([]model.Comment, error) {
+	return nil, ErrUnavailable
+}
+
+func (unavailableRepo) GetComment(ctx context.Context, id int) (model.Comment, error) {
+	return model.Comment{}, ErrUnavailable
+}
+
+func (unavailableRepo) CreateComment(ctx context.Context, comment *model.Comment) error {
+	return ErrUnavailable
+}
+
+func (unavailableRepo) SetCommentStatus(ctx context.Context, id int, status model.CommentStatus) (model.Comment, error) {
+	return model.Comment{}, ErrUnavailable
+}
+
+func (unavailableRepo) DeleteComment(ctx context.Context, id int) error {
+	return ErrUnavailable
+}
+
+func (unavailableRepo) HasApprovedComment(ctx context.Context, email string) (bool, error) {
+	return false, ErrUnavailable
+}
+
+func (unavailableRepo) GetMediaByOwner(ctx context.Context, owner string) ([]model.Media, error) {
+	return nil, ErrUnavailable
+}
+
+func (unavailableRepo) GetMedia(ctx context.Context, id int) (model.Media, error) {
+	return model.Media{}, ErrUnavailable
+}
+
+func (unavailableRepo) CreateMedia(ctx context.Context, media *model.Media) error {
+	return ErrUnavailable
+}
+
+func (unavailableRepo) DeleteMedia(ctx context.Context, id int) error {
+	return ErrUnavailable
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+type MemoryAuthorRepo struct {
+	store *MemoryStore
+}
+
+func NewMemoryAuthorRepo(store *MemoryStore) *MemoryAuthorRepo {
+	return &MemoryAuthorRepo{
+		store: store,
+	}
+}
+
+func (repo *MemoryAuthorRepo) GetAllAuthors(ctx context.Context) ([]model.Author, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var authors []model.Author
+	for _, a := range s.authors {
+		authors = append(authors, a)
+	}
+	slices.SortFunc(authors, func(a, b model.Author) int {
+		return cmp.Compare(a.Username, b.Username)
+	})
+	return authors, nil
+}
+
+func (repo *MemoryAuthorRepo) GetAuthorByUsername(ctx context.Context, username string) (model.Author, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.authors[username]
+	if !ok {
+		return model.Author{}, ErrNotFound
+	}
+	return a, nil
+}
+
+func (repo *MemoryAuthorRepo) GetAuthorByEmail(ctx context.Context, email string) (model.Author, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, a := range s.authors {
+		if a.Email == email {
+			return a, nil
+		}
+	}
+	return model.Author{}, ErrNotFound
+}
+
+func (repo *MemoryAuthorRepo) UsernameTaken(ctx context.Context, name string) (bool, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for username := range s.authors {
+		if strings.EqualFold(username, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (repo *MemoryAuthorRepo) CreateAuthor(ctx context.Context, author *model.Author) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.authors[author.Username]; ok {
+		return s.refuse(ctx, "authors", fmt.Errorf("%w: username already exists", ErrConflict))
+	}
+	if err := s.checkAuthor(author); err != nil {
+		return s.refuse(ctx, "authors", err)
+	}
+
+	s.authors[author.Username] = *author
+	return nil
+}
+
+func (repo *MemoryAuthorRepo) UpdateAuthor(ctx context.Context, username string, author *model.Author) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.authors[username]
+	if !ok {
+		return ErrNotFound
+	}
+
+	current.Email, current.Password, current.Role = author.Email, author.Password, author.Role
+	if err := s.checkAuthor(&current); err != nil {
+		return s.refuse(ctx, "authors", err)
+	}
+
+	s.authors[username] = current
+	return nil
+}
+
+// DeleteAuthor refuses to remove authors who still have entries.
+func (repo *MemoryAuthorRepo) DeleteAuthor(ctx context.Context, username string) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.authors[username]; !ok {
+		return ErrNotFound
+	}
+	for _, e := range s.entries {
+		if e.Author == username {
+			return s.refuse(ctx, "authors", errReferenced)
+		}
+	}
+
+	delete(s.authors, username)
+	return nil
+}
+
+// checkAuthor applies the constraints of the authors table to author,
+// which is about to be stored under its username.
+func (s *MemoryStore) checkAuthor(author *model.Author) error {
+	if !author.Role.Valid() {
+		return ErrInvalid
+	}
+	for _, a := range s.authors {
+		if a.Username != author.Username && a.Email == author.Email {
+			return fmt.Errorf("%w: email already exists", ErrConflict)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+func TestMemoryAuthorRepo(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(nil)
+	repo := NewMemoryAuthorRepo(store)
+
+	ana := model.Author{Username: "ana", Email: "ana@example.com", Password: "hash", Role: model.RoleWriter}
+	if err := repo.CreateAuthor(ctx, &ana); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bia := model.Author{Username: "bia", Email: "bia@example.com", Password: "hash", Role: model.RoleEditor}
+	if err := repo.CreateAuthor(ctx, &bia); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("unique username and email", func(t *testing.T) {
+		for _, a := range []model.Author{
+			{Username: "ana", Email: "other@example.com", Role: model.RoleWriter},
+			{Username: "carla", Email: "ana@example.com", Role: model.RoleWriter},
+		} {
+			if err := repo.CreateAuthor(ctx, &a); !errors.Is(err, ErrConflict) {
+				t.Errorf("Expected ErrConflict for %s, got %v", a.Username, err)
+			}
+		}
+
+		update := bia
+		update.Email = ana.Email
+		if err := repo.UpdateAuthor(ctx, "bia", &update); !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		a := model.Author{Username: "carla", Email: "carla@example.com", Role: "owner"}
+		if err := repo.CreateAuthor(ctx, &a); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Expected ErrInvalid, got %v", err)
+		}
+	})
+
+	t.Run("lookups", func(t *testing.T) {
+		got, err := repo.GetAuthorByEmail(ctx, "bia@example.com")
+		if err != nil || got.Username != "bia" {
+			t.Errorf("Expected bia, got %+v (%v)", got, err)
+		}
+		if _, err := repo.GetAuthorByUsername(ctx, "nobody"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+
+		all, err := repo.GetAllAuthors(ctx)
+		if err != nil || len(all) != 2 || all[0].Username != "ana" {
+			t.Errorf("Expected ana and bia, got %+v (%v)", all, err)
+		}
+
+		if taken, err := repo.UsernameTaken(ctx, "ANA"); err != nil || !taken {
+			t.Errorf("Expected ANA to be taken, got %v (%v)", taken, err)
+		}
+		if taken, err := repo.UsernameTaken(ctx, "carla"); err != nil || taken {
+			t.Errorf("Expected carla to be free, got %v (%v)", taken, err)
+		}
+	})
+
+	t.Run("update keeps the username", func(t *testing.T) {
+		update := model.Author{Username: "renamed", Email: "ana@example.org", Password: "new", Role: model.RoleEditor}
+		if err := repo.UpdateAuthor(ctx, "ana", &update); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		got, _ := repo.GetAuthorByUsername(ctx, "ana")
+		if got.Email != "ana@example.org" || got.Role != model.RoleEditor {
+			t.Errorf("Expected the update to be stored, got %+v", got)
+		}
+		if err := repo.UpdateAuthor(ctx, "nobody", &update); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		entry := model.Entry{Title: "Post", Slug: "post", Body: "Body", BodyFormat: model.FormatPlain, Author: "bia", Status: model.StatusDraft}
+		if err := NewMemoryEntryRepo(store).CreateEntry(ctx, &entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if err := repo.DeleteAuthor(ctx, "bia"); !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected authors with entries to be kept, got %v", err)
+		}
+		if err := repo.DeleteAuthor(ctx, "ana"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if err := repo.DeleteAuthor(ctx, "ana"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
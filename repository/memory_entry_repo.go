@@ -0,0 +1,453 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+type MemoryEntryRepo struct {
+	store *MemoryStore
+}
+
+func NewMemoryEntryRepo(store *MemoryStore) *MemoryEntryRepo {
+	return &MemoryEntryRepo{
+		store: store,
+	}
+}
+
+func (repo *MemoryEntryRepo) GetAllEntries(ctx context.Context, query model.EntryQuery) (model.EntryPage, error) {
+	if _, ok := entrySortColumns[query.Sort]; !ok {
+		return model.EntryPage{}, fmt.Errorf("%w: unknown sort %q", ErrInvalid, query.Sort)
+	}
+
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+	var matched []model.Entry
+	for _, e := range s.entries {
+		if matchesEntryQuery(e, query, now) {
+			matched = append(matched, e)
+		}
+	}
+
+	compare := func(a, b model.Entry) int {
+		c := compareEntries(query.Sort, a, b)
+		if query.Order == model.Descending {
+			return -c
+		}
+		return c
+	}
+	slices.SortFunc(matched, compare)
+
+	page := model.EntryPage{Limit: query.Limit, Offset: query.Offset, Total: len(matched)}
+
+	if query.Cursor != "" {
+		value, id, err := decodeEntryCursor(query.Sort, query.Cursor)
+		if err != nil {
+			return model.EntryPage{}, err
+		}
+
+		last := model.Entry{ID: id}
+		switch v := value.(type) {
+		case time.Time:
+			last.CreatedAt, last.PublishedAt = v, &v
+		case string:
+			last.Title = v
+		}
+		matched = slices.DeleteFunc(matched, func(e model.Entry) bool { return compare(e, last) <= 0 })
+	}
+
+	matched = matched[min(query.Offset, len(matched)):]
+	for _, e := range matched[:min(query.Limit+1, len(matched))] {
+		page.Entries = append(page.Entries, cloneEntry(e))
+	}
+
+	if len(page.Entries) > query.Limit {
+		page.Entries = page.Entries[:query.Limit]
+		page.NextCursor = encodeEntryCursor(query.Sort, page.Entries[len(page.Entries)-1])
+	}
+
+	return page, nil
+}
+
+func matchesEntryQuery(e model.Entry, query model.EntryQuery, now time.Time) bool {
+	if query.Status == model.StatusPublished {
+		if !e.IsPublic(now) {
+			return false
+		}
+	} else if e.Status != query.Status {
+		return false
+	}
+
+	if query.Author != "" && e.Author != query.Author {
+		return false
+	}
+	if len(query.Tags) > 0 {
+		tagged := 0
+		for _, name := range e.Tags {
+			if slices.Contains(query.Tags, name) {
+				tagged++
+			}
+		}
+		if tagged == 0 || query.TagMatch == model.MatchAllTags && tagged != len(query.Tags) {
+			return false
+		}
+	}
+	if query.From != nil && e.CreatedAt.Before(*query.From) {
+		return false
+	}
+	if query.To != nil && !e.CreatedAt.Before(*query.To) {
+		return false
+	}
+	return true
+}
+
+// compareEntries orders entries by sort, then by ID, the way the Postgres
+// listing does. Entries without a publication date sort last, like NULLs.
+func compareEntries(sort model.EntrySort, a, b model.Entry) int {
+	var c int
+	switch sort {
+	case model.SortByCreatedAt:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	case model.SortByPublishedAt:
+		switch {
+		case a.PublishedAt == nil && b.PublishedAt == nil:
+		case a.PublishedAt == nil:
+			c = 1
+		case b.PublishedAt == nil:
+			c = -1
+		default:
+			c = a.PublishedAt.Compare(*b.PublishedAt)
+		}
+	case model.SortByTitle:
+		c = strings.Compare(a.Title, b.Title)
+	}
+	return cmp.Or(c, cmp.Compare(a.ID, b.ID))
+}
+
+func (repo *MemoryEntryRepo) GetEntryById(ctx context.Context, id int) (model.Entry, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return model.Entry{}, ErrNotFound
+	}
+	return cloneEntry(e), nil
+}
+
+func (repo *MemoryEntryRepo) GetEntryBySlug(ctx context.Context, slug string) (model.Entry, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, e := range s.entries {
+		if e.Slug == slug {
+			return cloneEntry(e), nil
+		}
+	}
+	return model.Entry{}, ErrNotFound
+}
+
+// GetEntryByOldSlug finds the entry that used slug before being renamed.
+func (repo *MemoryEntryRepo) GetEntryByOldSlug(ctx context.Context, slug string) (model.Entry, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.oldSlugs[slug]
+	if !ok {
+		return model.Entry{}, ErrNotFound
+	}
+	return cloneEntry(s.entries[id]), nil
+}
+
+// SlugsWithPrefix lists the current and old slugs that are prefix itself or
+// prefix followed by a hyphenated suffix.
+func (repo *MemoryEntryRepo) SlugsWithPrefix(ctx context.Context, prefix string) ([]string, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := func(slug string) bool {
+		return slug == prefix || strings.HasPrefix(slug, prefix+"-")
+	}
+
+	var slugs []string
+	for _, e := range s.entries {
+		if matches(e.Slug) {
+			slugs = append(slugs, e.Slug)
+		}
+	}
+	for slug := range s.oldSlugs {
+		if matches(slug) && !slices.Contains(slugs, slug) {
+			slugs = append(slugs, slug)
+		}
+	}
+	slices.Sort(slugs)
+	return slugs, nil
+}
+
+// SearchEntries finds published entries where every word of the query
+// starts a word of their title or body, ignoring case. Prefixes stand in
+// for the stemming Postgres does, and the web search syntax is not
+// understood; entries rank by how many words match.
+func (repo *MemoryEntryRepo) SearchEntries(ctx context.Context, query model.SearchQuery) ([]model.SearchResult, error) {
+	terms := searchWords(query.Text)
+	if len(terms) == 0 {
+		return []model.SearchResult{}, nil
+	}
+
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := []model.SearchResult{}
+	now := s.now()
+	for _, e := range s.entries {
+		if !e.IsPublic(now) {
+			continue
+		}
+
+		words := searchWords(e.Title + " " + e.Body)
+		rank := 0
+		for _, term := range terms {
+			n := 0
+			for _, word := range words {
+				if strings.HasPrefix(word, term) {
+					n++
+				}
+			}
+			if n == 0 {
+				rank = 0
+				break
+			}
+			rank += n
+		}
+		if rank == 0 {
+			continue
+		}
+
+		results = append(results, model.SearchResult{
+			Entry:   cloneEntry(e),
+			Rank:    float64(rank),
+			Snippet: searchSnippet(e.Body, terms),
+		})
+	}
+
+	slices.SortFunc(results, func(a, b model.SearchResult) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(b.Entry.ID, a.Entry.ID))
+	})
+	results = results[min(query.Offset, len(results)):]
+	return results[:min(query.Limit, len(results))], nil
+}
+
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchSnippet marks the words of body matching a term, keeping about as
+// much text around the first match as ts_headline would.
+func searchSnippet(body string, terms []string) string {
+	words := strings.Fields(body)
+	first := -1
+	for i, word := range words {
+		matches := slices.ContainsFunc(searchWords(word), func(w string) bool {
+			return slices.ContainsFunc(terms, func(term string) bool { return strings.HasPrefix(w, term) })
+		})
+		if matches {
+			words[i] = highlightStart + word + highlightStop
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+
+	start := max(first-10, 0)
+	end := min(start+35, len(words))
+	return highlight(strings.Join(words[start:end], " "))
+}
+
+func (repo *MemoryEntryRepo) CreateEntry(ctx context.Context, entry *model.Entry) error {
+	if err := renderEntry(entry); err != nil {
+		return err
+	}
+
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkEntry(0, entry); err != nil {
+		return s.refuse(ctx, "entries", err)
+	}
+
+	s.lastEntryID++
+	entry.ID = s.lastEntryID
+	entry.CreatedAt = s.now()
+	entry.UpdatedAt = entry.CreatedAt
+	s.entries[entry.ID] = storedEntry(*entry)
+	return nil
+}
+
+// UpdateEntry snapshots the stored entry as a new revision before
+// overwriting it. A changed slug is remembered so links to it can redirect.
+func (repo *MemoryEntryRepo) UpdateEntry(ctx context.Context, id int, entry *model.Entry) error {
+	if err := renderEntry(entry); err != nil {
+		return err
+	}
+
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.entries[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if err := s.checkEntry(id, entry); err != nil {
+		return s.refuse(ctx, "entries", err)
+	}
+
+	now := s.now()
+	s.revisions[id] = append(s.revisions[id], model.Revision{
+		EntryID:    id,
+		Number:     len(s.revisions[id]) + 1,
+		Title:      current.Title,
+		Slug:       current.Slug,
+		Body:       current.Body,
+		BodyFormat: current.BodyFormat,
+		CreatedAt:  now,
+	})
+
+	if entry.Slug != current.Slug {
+		s.oldSlugs[current.Slug] = id
+		// Renaming back to an old slug makes it current again.
+		if s.oldSlugs[entry.Slug] == id {
+			delete(s.oldSlugs, entry.Slug)
+		}
+	}
+
+	entry.UpdatedAt = now
+	stored := storedEntry(*entry)
+	stored.ID, stored.CreatedAt = id, current.CreatedAt
+	s.entries[id] = stored
+	return nil
+}
+
+// DeleteEntry removes an entry together with its revisions and old slugs.
+func (repo *MemoryEntryRepo) DeleteEntry(ctx context.Context, id int) error {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(s.entries, id)
+	delete(s.revisions, id)
+	for slug, entryID := range s.oldSlugs {
+		if entryID == id {
+			delete(s.oldSlugs, slug)
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryEntryRepo) PublishDueEntries(ctx context.Context, now time.Time) ([]model.Entry, error) {
+	s := repo.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []model.Entry
+	for id, e := range s.entries {
+		if e.Status != model.StatusScheduled || e.PublishedAt.After(now) {
+			continue
+		}
+		e.Status, e.UpdatedAt = model.StatusPublished, now
+		s.entries[id] = e
+		entries = append(entries, cloneEntry(e))
+	}
+	slices.SortFunc(entries, func(a, b model.Entry) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return entries, nil
+}
+
+func (repo *MemoryEntryRepo) GetRevisions(ctx context.Context, entryID int) ([]model.Revision, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := slices.Clone(s.revisions[entryID])
+	slices.Reverse(revisions)
+	if revisions == nil {
+		revisions = []model.Revision{}
+	}
+	return revisions, nil
+}
+
+func (repo *MemoryEntryRepo) GetRevision(ctx context.Context, entryID, number int) (model.Revision, error) {
+	s := repo.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := s.revisions[entryID]
+	if number < 1 || number > len(revisions) {
+		return model.Revision{}, ErrNotFound
+	}
+	return revisions[number-1], nil
+}
+
+// checkEntry applies the constraints of the entries table to entry, which
+// is about to be stored under id, or under a new ID when id is 0.
+func (s *MemoryStore) checkEntry(id int, entry *model.Entry) error {
+	if _, ok := s.authors[entry.Author]; !ok {
+		return errReferenced
+	}
+	if !entry.Status.Valid() {
+		return ErrInvalid
+	}
+	if (entry.Status == model.StatusPublished || entry.Status == model.StatusScheduled) && entry.PublishedAt == nil {
+		return ErrInvalid
+	}
+	for _, e := range s.entries {
+		if e.ID != id && e.Slug == entry.Slug {
+			return fmt.Errorf("%w: slug already exists", ErrConflict)
+		}
+	}
+	return nil
+}
+
+// storedEntry copies e with its tags deduplicated and sorted, as loadTags
+// returns them, sharing no memory with the caller.
+func storedEntry(e model.Entry) model.Entry {
+	e = cloneEntry(e)
+	if e.Tags == nil {
+		e.Tags = []string{}
+	}
+	slices.Sort(e.Tags)
+	e.Tags = slices.Compact(e.Tags)
+	return e
+}
+
+func cloneEntry(e model.Entry) model.Entry {
+	e.Tags = slices.Clone(e.Tags)
+	if e.PublishedAt != nil {
+		published := *e.PublishedAt
+		e.PublishedAt = &published
+	}
+	return e
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+func newTestMemoryStore(t *testing.T) (*MemoryStore, *MemoryEntryRepo) {
+	t.Helper()
+
+	store := NewMemoryStore(nil)
+	author := model.Author{Username: "ana", Email: "ana@example.com", Role: model.RoleWriter}
+	if err := NewMemoryAuthorRepo(store).CreateAuthor(context.Background(), &author); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return store, NewMemoryEntryRepo(store)
+}
+
+func newMemoryEntry(slug string, status model.EntryStatus, published *time.Time, tags ...string) *model.Entry {
+	return &model.Entry{
+		Title:       slug,
+		Slug:        slug,
+		Body:        "Um post sobre " + slug,
+		BodyFormat:  model.FormatMarkdown,
+		Author:      "ana",
+		Status:      status,
+		PublishedAt: published,
+		Tags:        tags,
+	}
+}
+
+func TestMemoryEntryRepo_Constraints(t *testing.T) {
+	ctx := context.Background()
+	_, repo := newTestMemoryStore(t)
+
+	first := newMemoryEntry("first", model.StatusDraft, nil, "b", "a", "b")
+	if err := repo.CreateEntry(ctx, first); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first.ID != 1 || first.CreatedAt.IsZero() || first.BodyHTML == "" {
+		t.Errorf("Expected an ID, timestamps and rendering, got %+v", first)
+	}
+
+	got, err := repo.GetEntryById(ctx, first.ID)
+	if err != nil || fmt.Sprint(got.Tags) != "[a b]" {
+		t.Errorf("Expected sorted unique tags, got %v (%v)", got.Tags, err)
+	}
+
+	tests := []struct {
+		name  string
+		entry *model.Entry
+		want  error
+	}{
+		{"duplicate slug", newMemoryEntry("first", model.StatusDraft, nil), ErrConflict},
+		{"unknown author", &model.Entry{Slug: "x", Body: "x", BodyFormat: model.FormatPlain, Author: "nobody", Status: model.StatusDraft}, ErrConflict},
+		{"published without a date", newMemoryEntry("second", model.StatusPublished, nil), ErrInvalid},
+		{"unknown status", newMemoryEntry("second", "deleted", nil), ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := repo.CreateEntry(ctx, tt.entry); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("stored entries are copies", func(t *testing.T) {
+		got, _ := repo.GetEntryById(ctx, first.ID)
+		got.Tags[0] = "changed"
+
+		again, _ := repo.GetEntryById(ctx, first.ID)
+		if again.Tags[0] != "a" {
+			t.Errorf("Expected the store to be left alone, got %v", again.Tags)
+		}
+	})
+
+	t.Run("missing rows", func(t *testing.T) {
+		if err := repo.UpdateEntry(ctx, 99, newMemoryEntry("ghost", model.StatusDraft, nil)); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound on update, got %v", err)
+		}
+		if err := repo.DeleteEntry(ctx, 99); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound on delete, got %v", err)
+		}
+		if _, err := repo.GetEntryBySlug(ctx, "ghost"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound on lookup, got %v", err)
+		}
+	})
+}
+
+func TestMemoryEntryRepo_UpdateEntry(t *testing.T) {
+	ctx := context.Background()
+	_, repo := newTestMemoryStore(t)
+
+	entry := newMemoryEntry("hello", model.StatusDraft, nil)
+	if err := repo.CreateEntry(ctx, entry); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	renamed := newMemoryEntry("hello-world", model.StatusDraft, nil)
+	if err := repo.UpdateEntry(ctx, entry.ID, renamed); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	got, err := repo.GetEntryBySlug(ctx, "hello-world")
+	if err != nil || got.ID != entry.ID || !got.CreatedAt.Equal(entry.CreatedAt) {
+		t.Errorf("Expected the entry under its new slug, got %+v (%v)", got, err)
+	}
+	if got, err := repo.GetEntryByOldSlug(ctx, "hello"); err != nil || got.ID != entry.ID {
+		t.Errorf("Expected the old slug to lead to the entry, got %+v (%v)", got, err)
+	}
+
+	slugs, _ := repo.SlugsWithPrefix(ctx, "hello")
+	if fmt.Sprint(slugs) != "[hello hello-world]" {
+		t.Errorf("Expected current and old slugs, got %v", slugs)
+	}
+
+	revisions, _ := repo.GetRevisions(ctx, entry.ID)
+	if len(revisions) != 1 || revisions[0].Number != 1 || revisions[0].Slug != "hello" {
+		t.Errorf("Expected the previous version as revision 1, got %+v", revisions)
+	}
+
+	if err := repo.UpdateEntry(ctx, entry.ID, newMemoryEntry("hello", model.StatusDraft, nil)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.GetEntryByOldSlug(ctx, "hello"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected renaming back to make the slug current again, got %v", err)
+	}
+	if r, err := repo.GetRevision(ctx, entry.ID, 2); err != nil || r.Slug != "hello-world" {
+		t.Errorf("Expected revision 2, got %+v (%v)", r, err)
+	}
+
+	if err := repo.DeleteEntry(ctx, entry.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.GetEntryByOldSlug(ctx, "hello-world"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected old slugs to go with the entry, got %v", err)
+	}
+	if revisions, _ := repo.GetRevisions(ctx, entry.ID); len(revisions) != 0 {
+		t.Errorf("Expected revisions to go with the entry, got %+v", revisions)
+	}
+}
+
+func TestMemoryEntryRepo_GetAllEntries(t *testing.T) {
+	ctx := context.Background()
+	store, repo := newTestMemoryStore(t)
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	future := time.Now().Add(time.Hour)
+	for i, e := range []*model.Entry{
+		newMemoryEntry("a", model.StatusPublished, &base, "go"),
+		newMemoryEntry("b", model.StatusPublished, &base, "go", "sql"),
+		newMemoryEntry("c", model.StatusPublished, &base, "sql"),
+		newMemoryEntry("d", model.StatusPublished, &future, "go"),
+		newMemoryEntry("e", model.StatusDraft, nil, "go"),
+	} {
+		created := base.Add(time.Duration(i) * time.Minute)
+		store.now = func() time.Time { return created }
+		if err := repo.CreateEntry(ctx, e); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	store.now = time.Now
+
+	query := model.EntryQuery{Limit: 2, Sort: model.SortByCreatedAt, Order: model.Descending, Status: model.StatusPublished}
+	page, err := repo.GetAllEntries(ctx, query)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page.Total != 3 || slugsOf(page.Entries) != "[c b]" || page.NextCursor == "" {
+		t.Errorf("Expected c and b out of 3 with a cursor, got %s of %d (%q)", slugsOf(page.Entries), page.Total, page.NextCursor)
+	}
+
+	query.Cursor = page.NextCursor
+	page, err = repo.GetAllEntries(ctx, query)
+	if err != nil || slugsOf(page.Entries) != "[a]" || page.NextCursor != "" {
+		t.Errorf("Expected the last page to hold a, got %s (%v)", slugsOf(page.Entries), err)
+	}
+
+	tests := []struct {
+		name  string
+		query model.EntryQuery
+		want  string
+	}{
+		{"any tag", model.EntryQuery{Tags: []string{"go", "sql"}}, "[a b c]"},
+		{"all tags", model.EntryQuery{Tags: []string{"go", "sql"}, TagMatch: model.MatchAllTags}, "[b]"},
+		{"author", model.EntryQuery{Author: "bia"}, "[]"},
+		{"drafts", model.EntryQuery{Status: model.StatusDraft}, "[e]"},
+		{"title", model.EntryQuery{Sort: model.SortByTitle, Order: model.Descending}, "[c b a]"},
+		{"offset", model.EntryQuery{Offset: 2}, "[c]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.query
+			q.Limit = 10
+			q.Sort = cmp.Or(q.Sort, model.SortByCreatedAt)
+			q.Status = cmp.Or(q.Status, model.StatusPublished)
+
+			page, err := repo.GetAllEntries(ctx, q)
+			if err != nil || slugsOf(page.Entries) != tt.want {
+				t.Errorf("Expected %s, got %s (%v)", tt.want, slugsOf(page.Entries), err)
+			}
+		})
+	}
+
+	t.Run("unknown sort", func(t *testing.T) {
+		if _, err := repo.GetAllEntries(ctx, model.EntryQuery{Sort: "rank"}); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Expected ErrInvalid, got %v", err)
+		}
+	})
+
+	t.Run("tags of published entries", func(t *testing.T) {
+		tags, _ := NewMemoryTagRepo(store).GetAllTags(ctx)
+		if fmt.Sprint(tags) != "[{go 2} {sql 2}]" {
+			t.Errorf("Expected go and sql twice each, got %v", tags)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		results, err := repo.SearchEntries(ctx, model.SearchQuery{Text: "POST sobre b", Limit: 10})
+		if err != nil || len(results) != 1 || results[0].Entry.Slug != "b" {
+			t.Fatalf("Expected b, got %+v (%v)", results, err)
+		}
+		if results[0].Snippet != "Um <mark>post</mark> <mark>sobre</mark> <mark>b</mark>" {
+			t.Errorf("Expected matches to be marked, got %q", results[0].Snippet)
+		}
+	})
+
+	t.Run("publish due entries", func(t *testing.T) {
+		due := time.Now().Add(-time.Minute)
+		scheduled := newMemoryEntry("f", model.StatusScheduled, &due)
+		if err := repo.CreateEntry(ctx, scheduled); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		published, err := repo.PublishDueEntries(ctx, time.Now())
+		if err != nil || slugsOf(published) != "[f]" || published[0].Status != model.StatusPublished {
+			t.Errorf("Expected f to be published, got %+v (%v)", published, err)
+		}
+	})
+}
+
+func TestMemoryEntryRepo_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	_, repo := newTestMemoryStore(t)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() {
+			repo.CreateEntry(ctx, newMemoryEntry(fmt.Sprintf("post-%d", i), model.StatusDraft, nil))
+		})
+	}
+	wg.Wait()
+
+	page, err := repo.GetAllEntries(ctx, model.EntryQuery{Limit: 100, Sort: model.SortByCreatedAt, Status: model.StatusDraft})
+	if err != nil || page.Total != 50 {
+		t.Fatalf("Expected 50 entries, got %d (%v)", page.Total, err)
+	}
+	ids := make(map[int]bool)
+	for _, e := range page.Entries {
+		ids[e.ID] = true
+	}
+	if len(ids) != 50 {
+		t.Errorf("Expected 50 distinct IDs, got %d", len(ids))
+	}
+}
+
+func slugsOf(entries []model.Entry) string {
+	slugs := []string{}
+	for _, e := range entries {
+		slugs = append(slugs, e.Slug)
+	}
+	return fmt.Sprint(slugs)
+}
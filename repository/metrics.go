@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/juanplagos/bubble/metrics"
+)
+
+// RegisterPoolMetrics exposes the pool's statistics, read at scrape time.
+func RegisterPoolMetrics(reg *metrics.Registry, pool *pgxpool.Pool) {
+	reg.NewGaugeFunc("db_pool_acquired_conns", "Connections currently in use.", func() float64 {
+		return float64(pool.Stat().AcquiredConns())
+	})
+	reg.NewGaugeFunc("db_pool_idle_conns", "Connections open and waiting to be used.", func() float64 {
+		return float64(pool.Stat().IdleConns())
+	})
+	reg.NewGaugeFunc("db_pool_total_conns", "Connections open, in use, idle or being established.", func() float64 {
+		return float64(pool.Stat().TotalConns())
+	})
+	reg.NewGaugeFunc("db_pool_max_conns", "Most connections the pool will open.", func() float64 {
+		return float64(pool.Stat().MaxConns())
+	})
+	reg.NewCounterFunc("db_pool_acquires_total", "Connections handed out by the pool.", func() float64 {
+		return float64(pool.Stat().AcquireCount())
+	})
+	reg.NewCounterFunc("db_pool_empty_acquires_total", "Acquires that had to wait for a connection.", func() float64 {
+		return float64(pool.Stat().EmptyAcquireCount())
+	})
+	reg.NewCounterFunc("db_pool_acquire_wait_seconds_total", "Time spent waiting for a connection when none was free.", func() float64 {
+		return pool.Stat().EmptyAcquireWaitTime().Seconds()
+	})
+	reg.NewCounterFunc("db_pool_acquire_seconds_total", "Time spent acquiring connections, waits included.", func() float64 {
+		return pool.Stat().AcquireDuration().Seconds()
+	})
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/juanplagos/bubble/repository/migrations"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("ordered by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"0010_b.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+			"0010_b.down.sql": {Data: []byte("DROP TABLE b;")},
+			"0002_a.up.sql":   {Data: []byte("CREATE TABLE a ();")},
+			"0002_a.down.sql": {Data: []byte("DROP TABLE a;")},
+			"README":          {Data: []byte("ignored")},
+		}
+
+		got, err := LoadMigrations(fsys)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(got) != 2 || got[0].Version != 2 || got[1].Version != 10 {
+			t.Fatalf("Expected versions 2 and 10, got %+v", got)
+		}
+		if got[0].Name != "a" || got[0].Up != "CREATE TABLE a ();" || got[0].Down != "DROP TABLE a;" {
+			t.Errorf("Unexpected migration %+v", got[0])
+		}
+		if got[1].String() != "0010_b" {
+			t.Errorf("Expected 0010_b, got %s", got[1])
+		}
+	})
+
+	errorCases := map[string]fstest.MapFS{
+		"missing down": {
+			"0001_a.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"empty up": {
+			"0001_a.up.sql":   {Data: []byte("  \n")},
+			"0001_a.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"version reused": {
+			"0001_a.up.sql":   {Data: []byte("SELECT 1;")},
+			"0001_a.down.sql": {Data: []byte("SELECT 1;")},
+			"0001_b.up.sql":   {Data: []byte("SELECT 1;")},
+			"0001_b.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"bad name": {
+			"create_a.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadMigrations(fsys); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("Expected embedded migrations to load, got %v", err)
+	}
+
+	for i, m := range got {
+		if m.Version != i+1 {
+			t.Errorf("Expected version %d, got %s", i+1, m)
+		}
+	}
+}
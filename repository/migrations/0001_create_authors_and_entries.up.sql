@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS authors (
+    username TEXT PRIMARY KEY,
+    email    TEXT NOT NULL UNIQUE,
+    password TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS entries (
+    id         SERIAL PRIMARY KEY,
+    title      TEXT NOT NULL,
+    slug       TEXT NOT NULL UNIQUE,
+    body       TEXT NOT NULL,
+    author     TEXT NOT NULL REFERENCES authors (username),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
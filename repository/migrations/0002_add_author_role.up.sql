@@ -0,0 +1 @@
+ALTER TABLE authors ADD COLUMN role TEXT NOT NULL DEFAULT 'writer';
@@ -0,0 +1 @@
+ALTER TABLE authors DROP CONSTRAINT authors_role_check;
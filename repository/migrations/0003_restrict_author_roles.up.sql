@@ -0,0 +1,2 @@
+ALTER TABLE authors
+    ADD CONSTRAINT authors_role_check CHECK (role IN ('admin', 'editor', 'writer', 'reader'));
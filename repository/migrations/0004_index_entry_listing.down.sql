@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS entries_author_idx;
+DROP INDEX IF EXISTS entries_title_id_idx;
+DROP INDEX IF EXISTS entries_created_at_id_idx;
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS entries_created_at_id_idx ON entries (created_at, id);
+CREATE INDEX IF NOT EXISTS entries_title_id_idx ON entries (title, id);
+CREATE INDEX IF NOT EXISTS entries_author_idx ON entries (author);
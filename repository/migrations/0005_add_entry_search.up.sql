@@ -0,0 +1,8 @@
+-- Entries are mostly written in Portuguese; keep this configuration in sync
+-- with searchConfig in postgres_entry_repo.go.
+ALTER TABLE entries ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('portuguese', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('portuguese', coalesce(body, '')), 'B')
+) STORED;
+
+CREATE INDEX entries_search_vector_idx ON entries USING GIN (search_vector);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS entries_status_published_at_idx;
+ALTER TABLE entries
+    DROP CONSTRAINT entries_published_at_check,
+    DROP CONSTRAINT entries_status_check,
+    DROP COLUMN published_at,
+    DROP COLUMN status;
@@ -0,0 +1,13 @@
+-- Everything written before statuses existed was already public.
+ALTER TABLE entries
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'published',
+    ADD COLUMN published_at TIMESTAMPTZ;
+
+UPDATE entries SET published_at = created_at;
+
+ALTER TABLE entries
+    ALTER COLUMN status SET DEFAULT 'draft',
+    ADD CONSTRAINT entries_status_check CHECK (status IN ('draft', 'published', 'scheduled', 'archived')),
+    ADD CONSTRAINT entries_published_at_check CHECK (status NOT IN ('published', 'scheduled') OR published_at IS NOT NULL);
+
+CREATE INDEX entries_status_published_at_idx ON entries (status, published_at);
@@ -0,0 +1,10 @@
+-- Snapshots of an entry's content taken right before each update.
+CREATE TABLE entry_revisions (
+    entry_id   INTEGER NOT NULL REFERENCES entries (id) ON DELETE CASCADE,
+    revision   INTEGER NOT NULL,
+    title      TEXT NOT NULL,
+    slug       TEXT NOT NULL,
+    body       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (entry_id, revision)
+);
@@ -0,0 +1,8 @@
+-- Slugs entries used before being renamed, kept so old links keep working.
+CREATE TABLE entry_slugs (
+    slug       TEXT PRIMARY KEY,
+    entry_id   INTEGER NOT NULL REFERENCES entries (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX entry_slugs_entry_id_idx ON entry_slugs (entry_id);
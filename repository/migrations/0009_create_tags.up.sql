@@ -0,0 +1,13 @@
+CREATE TABLE tags (
+    id   SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE entry_tags (
+    entry_id INTEGER NOT NULL REFERENCES entries (id) ON DELETE CASCADE,
+    tag_id   INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (entry_id, tag_id)
+);
+
+-- The primary key serves lookups by entry; filtering by tag needs the reverse.
+CREATE INDEX entry_tags_tag_id_idx ON entry_tags (tag_id, entry_id);
@@ -0,0 +1,9 @@
+ALTER TABLE entry_revisions
+    DROP COLUMN body_format;
+
+ALTER TABLE entries
+    DROP CONSTRAINT entries_body_format_check,
+    DROP COLUMN reading_time,
+    DROP COLUMN excerpt,
+    DROP COLUMN body_html,
+    DROP COLUMN body_format;
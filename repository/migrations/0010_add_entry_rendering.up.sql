@@ -0,0 +1,11 @@
+-- Existing bodies are treated as markdown. Their rendered columns stay empty
+-- until the next update; the repository renders such rows when reading them.
+ALTER TABLE entries
+    ADD COLUMN body_format TEXT NOT NULL DEFAULT 'markdown',
+    ADD COLUMN body_html TEXT NOT NULL DEFAULT '',
+    ADD COLUMN excerpt TEXT NOT NULL DEFAULT '',
+    ADD COLUMN reading_time INTEGER NOT NULL DEFAULT 0,
+    ADD CONSTRAINT entries_body_format_check CHECK (body_format IN ('markdown', 'html', 'plain'));
+
+ALTER TABLE entry_revisions
+    ADD COLUMN body_format TEXT NOT NULL DEFAULT 'markdown';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS entries_published_at_idx;
+ALTER TABLE entries DROP COLUMN updated_at;
@@ -0,0 +1,10 @@
+ALTER TABLE entries ADD COLUMN updated_at TIMESTAMPTZ;
+
+UPDATE entries SET updated_at = GREATEST(created_at, COALESCE(published_at, created_at));
+
+ALTER TABLE entries
+    ALTER COLUMN updated_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT NOW();
+
+-- Feeds list published entries newest first.
+CREATE INDEX entries_published_at_idx ON entries (published_at, id) WHERE status = 'published';
@@ -0,0 +1,16 @@
+CREATE TABLE comments (
+    id           SERIAL PRIMARY KEY,
+    entry_id     INTEGER NOT NULL REFERENCES entries (id) ON DELETE CASCADE,
+    parent_id    INTEGER REFERENCES comments (id) ON DELETE CASCADE,
+    author_name  TEXT NOT NULL,
+    author_email TEXT NOT NULL,
+    body         TEXT NOT NULL,
+    status       TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'spam')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Threads are read per entry; the moderation queue is read per status.
+CREATE INDEX comments_entry_id_idx ON comments (entry_id, created_at, id);
+CREATE INDEX comments_status_idx ON comments (status, created_at, id);
+CREATE INDEX comments_approved_email_idx ON comments (author_email) WHERE status = 'approved';
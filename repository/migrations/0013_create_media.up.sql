@@ -0,0 +1,12 @@
+CREATE TABLE media (
+    id           SERIAL PRIMARY KEY,
+    owner        TEXT NOT NULL REFERENCES authors (username),
+    filename     TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size         BIGINT NOT NULL,
+    checksum     TEXT NOT NULL,
+    storage_key  TEXT NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX media_owner_idx ON media (owner, created_at DESC);
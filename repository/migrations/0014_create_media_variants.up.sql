@@ -0,0 +1,14 @@
+ALTER TABLE media
+    ADD COLUMN width  INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN height INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE media_variants (
+    media_id     INTEGER NOT NULL REFERENCES media (id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    width        INTEGER NOT NULL,
+    height       INTEGER NOT NULL,
+    content_type TEXT NOT NULL,
+    size         BIGINT NOT NULL,
+    storage_key  TEXT NOT NULL UNIQUE,
+    PRIMARY KEY (media_id, name)
+);
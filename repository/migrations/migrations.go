@@ -0,0 +1,9 @@
+// Package migrations holds the versioned SQL that defines the schema. Files
+// are named NNNN_description.up.sql and NNNN_description.down.sql and are
+// embedded so the binary can migrate a database on its own.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
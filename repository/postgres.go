@@ -1,36 +1,32 @@
 package repository
 
 import (
-    "context"
-    "fmt"
-    "log"
-    "os"
-    "github.com/jackc/pgx/v5/pgxpool"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func InitPostgresPool() *pgxpool.Pool {
-    user := os.Getenv("POSTGRES_USER")
-    password := os.Getenv("POSTGRES_PASSWORD")
-    host := os.Getenv("POSTGRES_HOST")
-    port := os.Getenv("POSTGRES_PORT")
-    dbname := os.Getenv("POSTGRES_DB")
-    
-	fmt.Println("se conectando a:", user, host, port, dbname)
+// InitPostgresPool connects to the database and checks it answers. Every
+// query made through the pool is traced to logger.
+func InitPostgresPool(ctx context.Context, connString string, logger *slog.Logger) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("configuração do banco de dados inválida: %w", err)
+	}
+	cfg.ConnConfig.Tracer = queryTracer{logger: logger}
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível criar a pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("não foi possível pingar o banco de dados: %w", err)
+	}
 
-    connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", 
-        user, password, host, port, dbname)
-    
-    pool, err := pgxpool.New(context.Background(), connStr)
-    if err != nil {
-        log.Fatalf("não foi possível criar a pool: %v\n", err)
-    }
-    
-    err = pool.Ping(context.Background())
-    if err != nil {
-        log.Fatalf("não foi possível pingar o banco de dados: %v\n", err)
-    }
-    
-    fmt.Println("pool do postgres pronta")
-    
-    return pool 
-}
\ No newline at end of file
+	logger.Info("pool do postgres pronta", "host", cfg.ConnConfig.Host, "database", cfg.ConnConfig.Database)
+	return pool, nil
+}
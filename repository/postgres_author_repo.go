@@ -8,12 +8,13 @@ import (
 )
 
 type AuthorRepo interface {
-	GetAllAuthors() ([]model.Author, error)
-	GetAuthorByUsername(username string) (model.Author, error)
-	GetAuthorByEmail(email string) (model.Author, error)
-	CreateAuthor(author *model.Author) error
-	UpdateAuthor(username string, author *model.Author) error
-	DeleteAuthor(username string) error
+	GetAllAuthors(ctx context.Context) ([]model.Author, error)
+	GetAuthorByUsername(ctx context.Context, username string) (model.Author, error)
+	GetAuthorByEmail(ctx context.Context, email string) (model.Author, error)
+	UsernameTaken(ctx context.Context, name string) (bool, error)
+	CreateAuthor(ctx context.Context, author *model.Author) error
+	UpdateAuthor(ctx context.Context, username string, author *model.Author) error
+	DeleteAuthor(ctx context.Context, username string) error
 }
 
 type PostgresAuthorRepo struct {
@@ -26,13 +27,13 @@ func NewPostgresAuthorRepo(pool *pgxpool.Pool) *PostgresAuthorRepo {
 	}
 }
 
-func (repo *PostgresAuthorRepo) GetAllAuthors() ([]model.Author, error) {
+func (repo *PostgresAuthorRepo) GetAllAuthors(ctx context.Context) ([]model.Author, error) {
 	rows, err := repo.pool.Query(
-		context.Background(),
-		"SELECT username, email, password FROM authors",
+		ctx,
+		"SELECT username, email, password, role FROM authors",
 	)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	defer rows.Close()
 
@@ -40,73 +41,102 @@ func (repo *PostgresAuthorRepo) GetAllAuthors() ([]model.Author, error) {
 
 	for rows.Next() {
 		var a model.Author
-		err := rows.Scan(&a.Username, &a.Email, &a.Password)
+		err := rows.Scan(&a.Username, &a.Email, &a.Password, &a.Role)
 		if err != nil {
-			return nil, err
+			return nil, translateError(err)
 		}
 		authors = append(authors, a)
 	}
 
 	if rows.Err() != nil {
-		return nil, rows.Err()
+		return nil, translateError(rows.Err())
 	}
 
 	return authors, nil
 }
 
-func (repo *PostgresAuthorRepo) GetAuthorByUsername(username string) (model.Author, error) {
+func (repo *PostgresAuthorRepo) GetAuthorByUsername(ctx context.Context, username string) (model.Author, error) {
 	var a model.Author
 	err := repo.pool.QueryRow(
-		context.Background(),
-		"SELECT username, email, password FROM authors WHERE username = $1",
+		ctx,
+		"SELECT username, email, password, role FROM authors WHERE username = $1",
 		username,
-	).Scan(&a.Username, &a.Email, &a.Password)
+	).Scan(&a.Username, &a.Email, &a.Password, &a.Role)
 
 	if err != nil {
-		return model.Author{}, err
+		return model.Author{}, translateError(err)
 	}
 
 	return a, nil
 }
 
-func (repo *PostgresAuthorRepo) GetAuthorByEmail(email string) (model.Author, error) {
+func (repo *PostgresAuthorRepo) GetAuthorByEmail(ctx context.Context, email string) (model.Author, error) {
 	var a model.Author
 	err := repo.pool.QueryRow(
-		context.Background(),
-		"SELECT username, email, password FROM authors WHERE email = $1",
+		ctx,
+		"SELECT username, email, password, role FROM authors WHERE email = $1",
 		email,
-	).Scan(&a.Username, &a.Email, &a.Password)
+	).Scan(&a.Username, &a.Email, &a.Password, &a.Role)
 
 	if err != nil {
-		return model.Author{}, err
+		return model.Author{}, translateError(err)
 	}
 
 	return a, nil
 }
 
-func (repo *PostgresAuthorRepo) CreateAuthor(author *model.Author) error {
+// UsernameTaken reports whether an author's username matches name when
+// case is ignored.
+func (repo *PostgresAuthorRepo) UsernameTaken(ctx context.Context, name string) (bool, error) {
+	var taken bool
+	err := repo.pool.QueryRow(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM authors WHERE lower(username) = lower($1))",
+		name,
+	).Scan(&taken)
+
+	if err != nil {
+		return false, translateError(err)
+	}
+
+	return taken, nil
+}
+
+func (repo *PostgresAuthorRepo) CreateAuthor(ctx context.Context, author *model.Author) error {
 	_, err := repo.pool.Exec(
-		context.Background(),
-		"INSERT INTO authors (username, email, password) VALUES ($1, $2, $3)",
-		author.Username, author.Email, author.Password,
+		ctx,
+		"INSERT INTO authors (username, email, password, role) VALUES ($1, $2, $3, $4)",
+		author.Username, author.Email, author.Password, author.Role,
 	)
-	return err
+	return translateError(err)
 }
 
-func (repo *PostgresAuthorRepo) UpdateAuthor(username string, author *model.Author) error {
-	_, err := repo.pool.Exec(
-		context.Background(),
-		"UPDATE authors SET email = $1, password = $2 WHERE username = $3",
-		author.Email, author.Password, username,
+func (repo *PostgresAuthorRepo) UpdateAuthor(ctx context.Context, username string, author *model.Author) error {
+	tag, err := repo.pool.Exec(
+		ctx,
+		"UPDATE authors SET email = $1, password = $2, role = $3 WHERE username = $4",
+		author.Email, author.Password, author.Role, username,
 	)
-	return err
+	if err != nil {
+		return translateError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
-func (repo *PostgresAuthorRepo) DeleteAuthor(username string) error {
-	_, err := repo.pool.Exec(
-		context.Background(),
+func (repo *PostgresAuthorRepo) DeleteAuthor(ctx context.Context, username string) error {
+	tag, err := repo.pool.Exec(
+		ctx,
 		"DELETE FROM authors WHERE username = $1",
 		username,
 	)
-	return err
+	if err != nil {
+		return translateError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
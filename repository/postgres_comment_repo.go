@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/juanplagos/bubble/model"
+)
+
+type CommentRepo interface {
+	GetEntryComments(ctx context.Context, entryID int, status model.CommentStatus) ([]model.Comment, error)
+	GetComments(ctx context.Context, query model.CommentQuery) ([]model.Comment, error)
+	GetComment(ctx context.Context, id int) (model.Comment, error)
+	CreateComment(ctx context.Context, comment *model.Comment) error
+	SetCommentStatus(ctx context.Context, id int, status model.CommentStatus) (model.Comment, error)
+	DeleteComment(ctx context.Context, id int) error
+	HasApprovedComment(ctx context.Context, email string) (bool, error)
+}
+
+type PostgresCommentRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresCommentRepo(pool *pgxpool.Pool) *PostgresCommentRepo {
+	return &PostgresCommentRepo{
+		pool: pool,
+	}
+}
+
+const commentColumns = "id, entry_id, parent_id, author_name, author_email, body, status, created_at"
+
+func scanComment(row scanner, c *model.Comment) error {
+	return row.Scan(&c.ID, &c.EntryID, &c.ParentID, &c.AuthorName, &c.AuthorEmail, &c.Body, &c.Status, &c.CreatedAt)
+}
+
+func collectComments(rows pgx.Rows) ([]model.Comment, error) {
+	defer rows.Close()
+
+	comments := []model.Comment{}
+
+	for rows.Next() {
+		var c model.Comment
+
+		err := scanComment(rows, &c)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		comments = append(comments, c)
+	}
+
+	if rows.Err() != nil {
+		return nil, translateError(rows.Err())
+	}
+
+	return comments, nil
+}
+
+// GetEntryComments returns every comment on an entry with the given status
+// in the order they were written, leaving threading to the caller.
+func (repo *PostgresCommentRepo) GetEntryComments(ctx context.Context, entryID int, status model.CommentStatus) ([]model.Comment, error) {
+	rows, err := repo.pool.Query(
+		ctx,
+		"SELECT "+commentColumns+" FROM comments WHERE entry_id = $1 AND status = $2 ORDER BY created_at, id",
+		entryID, status,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return collectComments(rows)
+}
+
+func (repo *PostgresCommentRepo) GetComments(ctx context.Context, query model.CommentQuery) ([]model.Comment, error) {
+	rows, err := repo.pool.Query(
+		ctx,
+		"SELECT "+commentColumns+" FROM comments WHERE status = $1 ORDER BY created_at, id LIMIT $2 OFFSET $3",
+		query.Status, query.Limit, query.Offset,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return collectComments(rows)
+}
+
+func (repo *PostgresCommentRepo) GetComment(ctx context.Context, id int) (model.Comment, error) {
+	var c model.Comment
+
+	err := scanComment(repo.pool.QueryRow(
+		ctx,
+		"SELECT "+commentColumns+" FROM comments WHERE id = $1",
+		id,
+	), &c)
+
+	if err != nil {
+		return model.Comment{}, translateError(err)
+	}
+
+	return c, nil
+}
+
+func (repo *PostgresCommentRepo) CreateComment(ctx context.Context, comment *model.Comment) error {
+	err := repo.pool.QueryRow(
+		ctx,
+		`INSERT INTO comments (entry_id, parent_id, author_name, author_email, body, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		comment.EntryID, comment.ParentID, comment.AuthorName, comment.AuthorEmail, comment.Body, comment.Status,
+	).Scan(&comment.ID, &comment.CreatedAt)
+
+	return translateError(err)
+}
+
+func (repo *PostgresCommentRepo) SetCommentStatus(ctx context.Context, id int, status model.CommentStatus) (model.Comment, error) {
+	var c model.Comment
+
+	err := scanComment(repo.pool.QueryRow(
+		ctx,
+		"UPDATE comments SET status = $2 WHERE id = $1 RETURNING "+commentColumns,
+		id, status,
+	), &c)
+
+	if err != nil {
+		return model.Comment{}, translateError(err)
+	}
+
+	return c, nil
+}
+
+// DeleteComment removes a comment together with its replies.
+func (repo *PostgresCommentRepo) DeleteComment(ctx context.Context, id int) error {
+	tag, err := repo.pool.Exec(
+		ctx,
+		"DELETE FROM comments WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// HasApprovedComment reports whether a comment from email was ever
+// approved. Emails are stored lowercased, so email must be too.
+func (repo *PostgresCommentRepo) HasApprovedComment(ctx context.Context, email string) (bool, error) {
+	var exists bool
+
+	err := repo.pool.QueryRow(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM comments WHERE author_email = $1 AND status = 'approved')",
+		email,
+	).Scan(&exists)
+
+	if err != nil {
+		return false, translateError(err)
+	}
+
+	return exists, nil
+}
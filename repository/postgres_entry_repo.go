@@ -2,18 +2,30 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/render"
 )
 
 type EntryRepo interface {
-	GetAllEntries() ([]model.Entry, error)
-	GetEntryById(id int) (model.Entry, error)
-	GetEntryBySlug(slug string) (model.Entry, error)
-	CreateEntry(entry *model.Entry) error
-	UpdateEntry(id int, entry *model.Entry) error
-	DeleteEntry(id int) error
+	GetAllEntries(ctx context.Context, query model.EntryQuery) (model.EntryPage, error)
+	GetEntryById(ctx context.Context, id int) (model.Entry, error)
+	GetEntryBySlug(ctx context.Context, slug string) (model.Entry, error)
+	GetEntryByOldSlug(ctx context.Context, slug string) (model.Entry, error)
+	SlugsWithPrefix(ctx context.Context, prefix string) ([]string, error)
+	SearchEntries(ctx context.Context, query model.SearchQuery) ([]model.SearchResult, error)
+	CreateEntry(ctx context.Context, entry *model.Entry) error
+	UpdateEntry(ctx context.Context, id int, entry *model.Entry) error
+	DeleteEntry(ctx context.Context, id int) error
+	PublishDueEntries(ctx context.Context, now time.Time) ([]model.Entry, error)
+	GetRevisions(ctx context.Context, entryID int) ([]model.Revision, error)
+	GetRevision(ctx context.Context, entryID, number int) (model.Revision, error)
 }
 
 type PostgresEntryRepo struct {
@@ -26,85 +38,569 @@ func NewPostgresEntryRepo(pool *pgxpool.Pool) *PostgresEntryRepo {
 	}
 }
 
-func (repo *PostgresEntryRepo) GetAllEntries() ([]model.Entry, error) {
-	rows, err := repo.pool.Query(context.Background(), "SELECT id, title, slug, body, author, created_at FROM entries")
+const entryColumns = "id, title, slug, body, body_format, body_html, excerpt, reading_time, author, status, published_at, created_at, updated_at"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row scanner, e *model.Entry, extra ...any) error {
+	dest := append([]any{
+		&e.ID, &e.Title, &e.Slug, &e.Body, &e.BodyFormat, &e.BodyHTML, &e.Excerpt, &e.ReadingTime,
+		&e.Author, &e.Status, &e.PublishedAt, &e.CreatedAt, &e.UpdatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+
+	// Rows written before bodies were rendered are rendered on the fly until
+	// their next update stores the result.
+	if e.BodyHTML == "" && e.Body != "" {
+		return renderEntry(e)
+	}
+	return nil
+}
+
+// renderEntry fills in the rendered fields of an entry from its body, so
+// they are computed once per write instead of on every read.
+func renderEntry(e *model.Entry) error {
+	result, err := render.Body(e.BodyFormat, e.Body)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("%w: rendering body: %v", ErrInvalid, err)
 	}
-	defer rows.Close()
 
-	var entries []model.Entry
+	e.BodyHTML, e.Excerpt, e.ReadingTime = result.HTML, result.Excerpt, result.ReadingTime
+	return nil
+}
+
+var entrySortColumns = map[model.EntrySort]string{
+	model.SortByCreatedAt:   "created_at",
+	model.SortByPublishedAt: "published_at",
+	model.SortByTitle:       "title",
+}
+
+func (repo *PostgresEntryRepo) GetAllEntries(ctx context.Context, query model.EntryQuery) (model.EntryPage, error) {
+	column, ok := entrySortColumns[query.Sort]
+	if !ok {
+		return model.EntryPage{}, fmt.Errorf("%w: unknown sort %q", ErrInvalid, query.Sort)
+	}
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var filters []string
+	if query.Status == model.StatusPublished {
+		filters = append(filters, "status = 'published'", "published_at <= NOW()")
+	} else {
+		filters = append(filters, "status = "+arg(query.Status))
+	}
+	if query.Author != "" {
+		filters = append(filters, "author = "+arg(query.Author))
+	}
+	if len(query.Tags) > 0 {
+		tagged := "SELECT et.entry_id FROM entry_tags et JOIN tags t ON t.id = et.tag_id WHERE t.name = ANY(" + arg(query.Tags) + ")"
+		if query.TagMatch == model.MatchAllTags {
+			tagged += " GROUP BY et.entry_id HAVING COUNT(*) = " + arg(len(query.Tags))
+		}
+		filters = append(filters, "id IN ("+tagged+")")
+	}
+	if query.From != nil {
+		filters = append(filters, "created_at >= "+arg(*query.From))
+	}
+	if query.To != nil {
+		filters = append(filters, "created_at < "+arg(*query.To))
+	}
+
+	page := model.EntryPage{Limit: query.Limit, Offset: query.Offset}
+	err := repo.pool.QueryRow(
+		ctx,
+		"SELECT COUNT(*) FROM entries"+whereClause(filters),
+		args...,
+	).Scan(&page.Total)
+	if err != nil {
+		return model.EntryPage{}, translateError(err)
+	}
+
+	direction, comparison := "ASC", ">"
+	if query.Order == model.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if query.Cursor != "" {
+		value, id, err := decodeEntryCursor(query.Sort, query.Cursor)
+		if err != nil {
+			return model.EntryPage{}, err
+		}
+		filters = append(filters, fmt.Sprintf("(%s, id) %s (%s, %s)", column, comparison, arg(value), arg(id)))
+	}
+
+	sql := "SELECT " + entryColumns + " FROM entries" + whereClause(filters) +
+		fmt.Sprintf(" ORDER BY %s %s, id %s", column, direction, direction) +
+		" LIMIT " + arg(query.Limit+1) + " OFFSET " + arg(query.Offset)
+
+	rows, err := repo.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return model.EntryPage{}, translateError(err)
+	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var e model.Entry
 
-		err := rows.Scan(&e.ID, &e.Title, &e.Slug, &e.Body, &e.Author, &e.CreatedAt)
+		err := scanEntry(rows, &e)
 		if err != nil {
-			return nil, err
+			return model.EntryPage{}, translateError(err)
 		}
-		entries = append(entries, e)
+		page.Entries = append(page.Entries, e)
 	}
 
 	if rows.Err() != nil {
-		return nil, rows.Err()
+		return model.EntryPage{}, translateError(rows.Err())
 	}
 
-	return entries, nil
+	if len(page.Entries) > query.Limit {
+		page.Entries = page.Entries[:query.Limit]
+		page.NextCursor = encodeEntryCursor(query.Sort, page.Entries[len(page.Entries)-1])
+	}
+
+	if err := repo.loadTags(ctx, entryPointers(page.Entries)...); err != nil {
+		return model.EntryPage{}, err
+	}
+
+	return page, nil
 }
 
-func (repo *PostgresEntryRepo) GetEntryById(id int) (model.Entry, error) {
+func whereClause(filters []string) string {
+	if len(filters) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(filters, " AND ")
+}
+
+func (repo *PostgresEntryRepo) GetEntryById(ctx context.Context, id int) (model.Entry, error) {
 	var e model.Entry
-	err := repo.pool.QueryRow(
-		context.Background(),
-		"SELECT id, title, slug, body, author, created_at FROM entries WHERE id = $1",
+	err := scanEntry(repo.pool.QueryRow(
+		ctx,
+		"SELECT "+entryColumns+" FROM entries WHERE id = $1",
 		id,
-	).Scan(&e.ID, &e.Title, &e.Slug, &e.Body, &e.Author, &e.CreatedAt)
+	), &e)
 
 	if err != nil {
+		return model.Entry{}, translateError(err)
+	}
+
+	if err := repo.loadTags(ctx, &e); err != nil {
 		return model.Entry{}, err
 	}
 
 	return e, nil
 }
 
-func (repo *PostgresEntryRepo) GetEntryBySlug(slug string) (model.Entry, error) {
+func (repo *PostgresEntryRepo) GetEntryBySlug(ctx context.Context, slug string) (model.Entry, error) {
 	var e model.Entry
-	err := repo.pool.QueryRow(
-		context.Background(),
-		"SELECT id, title, slug, body, author, created_at FROM entries WHERE slug = $1",
+	err := scanEntry(repo.pool.QueryRow(
+		ctx,
+		"SELECT "+entryColumns+" FROM entries WHERE slug = $1",
 		slug,
-	).Scan(&e.ID, &e.Title, &e.Slug, &e.Body, &e.Author, &e.CreatedAt)
+	), &e)
 
 	if err != nil {
+		return model.Entry{}, translateError(err)
+	}
+
+	if err := repo.loadTags(ctx, &e); err != nil {
 		return model.Entry{}, err
 	}
 
 	return e, nil
 }
 
-func (repo *PostgresEntryRepo) CreateEntry(entry *model.Entry) error {
-	err := repo.pool.QueryRow(
-		context.Background(),
-		"INSERT INTO entries (title, slug, body, author, created_at) VALUES ($1, $2, $3, $4, NOW()) RETURNING id",
-		entry.Title, entry.Slug, entry.Body, entry.Author,
-	).Scan(&entry.ID)
-	return err
-}
-
-func (repo *PostgresEntryRepo) UpdateEntry(id int, entry *model.Entry) error {
-	_, err := repo.pool.Exec(
-		context.Background(),
-		"UPDATE entries SET title = $1, slug = $2, body = $3, author = $4 WHERE id = $5",
-		entry.Title, entry.Slug, entry.Body, entry.Author, id,
+// GetEntryByOldSlug finds the entry that used slug before being renamed.
+func (repo *PostgresEntryRepo) GetEntryByOldSlug(ctx context.Context, slug string) (model.Entry, error) {
+	var e model.Entry
+	err := scanEntry(repo.pool.QueryRow(
+		ctx,
+		"SELECT "+entryColumns+" FROM entries WHERE id = (SELECT entry_id FROM entry_slugs WHERE slug = $1)",
+		slug,
+	), &e)
+
+	if err != nil {
+		return model.Entry{}, translateError(err)
+	}
+
+	if err := repo.loadTags(ctx, &e); err != nil {
+		return model.Entry{}, err
+	}
+
+	return e, nil
+}
+
+// SlugsWithPrefix lists the current and old slugs that are prefix itself or
+// prefix followed by a hyphenated suffix.
+func (repo *PostgresEntryRepo) SlugsWithPrefix(ctx context.Context, prefix string) ([]string, error) {
+	rows, err := repo.pool.Query(
+		ctx,
+		`SELECT slug FROM entries WHERE slug = $1 OR slug LIKE $1 || '-%'
+		UNION
+		SELECT slug FROM entry_slugs WHERE slug = $1 OR slug LIKE $1 || '-%'`,
+		prefix,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	var slugs []string
+
+	for rows.Next() {
+		var slug string
+
+		err := rows.Scan(&slug)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		slugs = append(slugs, slug)
+	}
+
+	if rows.Err() != nil {
+		return nil, translateError(rows.Err())
+	}
+
+	return slugs, nil
+}
+
+// searchConfig is the text search configuration used to build
+// entries.search_vector; queries must use the same one to match its stems.
+const searchConfig = "portuguese"
+
+// ts_headline does not escape the surrounding text, so matches are marked
+// with control characters and turned into <mark> tags after escaping.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+func (repo *PostgresEntryRepo) SearchEntries(ctx context.Context, query model.SearchQuery) ([]model.SearchResult, error) {
+	rows, err := repo.pool.Query(
+		ctx,
+		`SELECT `+entryColumns+`,
+			ts_rank_cd(search_vector, q) AS rank,
+			ts_headline($1::regconfig, body, q, $2) AS snippet
+		FROM entries, websearch_to_tsquery($1::regconfig, $3) AS q
+		WHERE search_vector @@ q AND status = 'published' AND published_at <= NOW()
+		ORDER BY rank DESC, id DESC
+		LIMIT $4 OFFSET $5`,
+		searchConfig,
+		"StartSel="+highlightStart+", StopSel="+highlightStop+", MaxWords=35, MinWords=15, MaxFragments=2",
+		query.Text, query.Limit, query.Offset,
 	)
-	return err
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	results := []model.SearchResult{}
+
+	for rows.Next() {
+		var r model.SearchResult
+
+		err := scanEntry(rows, &r.Entry, &r.Rank, &r.Snippet)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		r.Snippet = highlight(r.Snippet)
+		results = append(results, r)
+	}
+
+	if rows.Err() != nil {
+		return nil, translateError(rows.Err())
+	}
+
+	entries := make([]*model.Entry, len(results))
+	for i := range results {
+		entries[i] = &results[i].Entry
+	}
+	if err := repo.loadTags(ctx, entries...); err != nil {
+		return nil, err
+	}
+
+	return results, nil
 }
 
-func (repo *PostgresEntryRepo) DeleteEntry(id int) error {
-	_, err := repo.pool.Exec(
-		context.Background(),
+func highlight(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, highlightStart, "<mark>")
+	return strings.ReplaceAll(snippet, highlightStop, "</mark>")
+}
+
+func (repo *PostgresEntryRepo) CreateEntry(ctx context.Context, entry *model.Entry) error {
+	if err := renderEntry(entry); err != nil {
+		return err
+	}
+
+	tx, err := repo.pool.Begin(ctx)
+	if err != nil {
+		return translateError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO entries (title, slug, body, body_format, body_html, excerpt, reading_time, author, status, published_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW()) RETURNING id, created_at, updated_at`,
+		entry.Title, entry.Slug, entry.Body, entry.BodyFormat, entry.BodyHTML, entry.Excerpt, entry.ReadingTime,
+		entry.Author, entry.Status, entry.PublishedAt,
+	).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if err := setEntryTags(ctx, tx, entry.ID, entry.Tags); err != nil {
+		return err
+	}
+
+	return translateError(tx.Commit(ctx))
+}
+
+// UpdateEntry snapshots the stored entry into entry_revisions and overwrites
+// it in a single transaction, so no update can lose the previous content. A
+// changed slug is remembered in entry_slugs so links to it can redirect.
+func (repo *PostgresEntryRepo) UpdateEntry(ctx context.Context, id int, entry *model.Entry) error {
+	if err := renderEntry(entry); err != nil {
+		return err
+	}
+
+	tx, err := repo.pool.Begin(ctx)
+	if err != nil {
+		return translateError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the row first serialises concurrent updates, so each one sees
+	// the revision numbers the previous one wrote.
+	var oldSlug string
+	err = tx.QueryRow(ctx, "SELECT slug FROM entries WHERE id = $1 FOR UPDATE", id).Scan(&oldSlug)
+	if err != nil {
+		return translateError(err)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO entry_revisions (entry_id, revision, title, slug, body, body_format, created_at)
+		SELECT id, COALESCE((SELECT MAX(revision) FROM entry_revisions WHERE entry_id = $1), 0) + 1, title, slug, body, body_format, NOW()
+		FROM entries WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	err = tx.QueryRow(
+		ctx,
+		`UPDATE entries SET title = $1, slug = $2, body = $3, body_format = $4, body_html = $5, excerpt = $6, reading_time = $7,
+		author = $8, status = $9, published_at = $10, updated_at = NOW() WHERE id = $11 RETURNING updated_at`,
+		entry.Title, entry.Slug, entry.Body, entry.BodyFormat, entry.BodyHTML, entry.Excerpt, entry.ReadingTime,
+		entry.Author, entry.Status, entry.PublishedAt, id,
+	).Scan(&entry.UpdatedAt)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if entry.Slug != oldSlug {
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO entry_slugs (slug, entry_id, created_at) VALUES ($1, $2, NOW())
+			ON CONFLICT (slug) DO UPDATE SET entry_id = EXCLUDED.entry_id, created_at = EXCLUDED.created_at`,
+			oldSlug, id,
+		)
+		if err != nil {
+			return translateError(err)
+		}
+
+		// Renaming back to an old slug makes it current again.
+		_, err = tx.Exec(ctx, "DELETE FROM entry_slugs WHERE slug = $1 AND entry_id = $2", entry.Slug, id)
+		if err != nil {
+			return translateError(err)
+		}
+	}
+
+	if err := setEntryTags(ctx, tx, id, entry.Tags); err != nil {
+		return err
+	}
+
+	return translateError(tx.Commit(ctx))
+}
+
+func (repo *PostgresEntryRepo) DeleteEntry(ctx context.Context, id int) error {
+	tag, err := repo.pool.Exec(
+		ctx,
 		"DELETE FROM entries WHERE id = $1",
 		id,
 	)
-	return err
+	if err != nil {
+		return translateError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (repo *PostgresEntryRepo) PublishDueEntries(ctx context.Context, now time.Time) ([]model.Entry, error) {
+	rows, err := repo.pool.Query(
+		ctx,
+		"UPDATE entries SET status = 'published', updated_at = $1 WHERE status = 'scheduled' AND published_at <= $1 RETURNING "+entryColumns,
+		now,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	var entries []model.Entry
+
+	for rows.Next() {
+		var e model.Entry
+
+		err := scanEntry(rows, &e)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		entries = append(entries, e)
+	}
+
+	if rows.Err() != nil {
+		return nil, translateError(rows.Err())
+	}
+
+	if err := repo.loadTags(ctx, entryPointers(entries)...); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// setEntryTags replaces the tags of an entry, creating the ones that do not
+// exist yet.
+func setEntryTags(ctx context.Context, tx pgx.Tx, entryID int, tags []string) error {
+	_, err := tx.Exec(ctx, "DELETE FROM entry_tags WHERE entry_id = $1", entryID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING", tags)
+	if err != nil {
+		return translateError(err)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"INSERT INTO entry_tags (entry_id, tag_id) SELECT $1, id FROM tags WHERE name = ANY($2)",
+		entryID, tags,
+	)
+	return translateError(err)
+}
+
+// loadTags fills in the tags of the given entries with a single query.
+func (repo *PostgresEntryRepo) loadTags(ctx context.Context, entries ...*model.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*model.Entry, len(entries))
+	ids := make([]int, 0, len(entries))
+	for _, e := range entries {
+		e.Tags = []string{}
+		byID[e.ID] = e
+		ids = append(ids, e.ID)
+	}
+
+	rows, err := repo.pool.Query(
+		ctx,
+		"SELECT et.entry_id, t.name FROM entry_tags et JOIN tags t ON t.id = et.tag_id WHERE et.entry_id = ANY($1) ORDER BY t.name",
+		ids,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var name string
+
+		err := rows.Scan(&id, &name)
+		if err != nil {
+			return translateError(err)
+		}
+		byID[id].Tags = append(byID[id].Tags, name)
+	}
+
+	return translateError(rows.Err())
+}
+
+func entryPointers(entries []model.Entry) []*model.Entry {
+	ptrs := make([]*model.Entry, len(entries))
+	for i := range entries {
+		ptrs[i] = &entries[i]
+	}
+	return ptrs
+}
+
+const revisionColumns = "entry_id, revision, title, slug, body, body_format, created_at"
+
+func scanRevision(row scanner, r *model.Revision) error {
+	return row.Scan(&r.EntryID, &r.Number, &r.Title, &r.Slug, &r.Body, &r.BodyFormat, &r.CreatedAt)
+}
+
+func (repo *PostgresEntryRepo) GetRevisions(ctx context.Context, entryID int) ([]model.Revision, error) {
+	rows, err := repo.pool.Query(
+		ctx,
+		"SELECT "+revisionColumns+" FROM entry_revisions WHERE entry_id = $1 ORDER BY revision DESC",
+		entryID,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	revisions := []model.Revision{}
+
+	for rows.Next() {
+		var r model.Revision
+
+		err := scanRevision(rows, &r)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		revisions = append(revisions, r)
+	}
+
+	if rows.Err() != nil {
+		return nil, translateError(rows.Err())
+	}
+
+	return revisions, nil
+}
+
+func (repo *PostgresEntryRepo) GetRevision(ctx context.Context, entryID, number int) (model.Revision, error) {
+	var r model.Revision
+	err := scanRevision(repo.pool.QueryRow(
+		ctx,
+		"SELECT "+revisionColumns+" FROM entry_revisions WHERE entry_id = $1 AND revision = $2",
+		entryID, number,
+	), &r)
+
+	if err != nil {
+		return model.Revision{}, translateError(err)
+	}
+
+	return r, nil
 }
@@ -0,0 +1,12 @@
+package repository
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	got := highlight("um <b>post</b> sobre " + highlightStart + "gatos" + highlightStop + " & cães")
+	want := "um &lt;b&gt;post&lt;/b&gt; sobre <mark>gatos</mark> &amp; cães"
+
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
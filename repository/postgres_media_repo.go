@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/juanplagos/bubble/model"
+)
+
+type MediaRepo interface {
+	GetMediaByOwner(ctx context.Context, owner string) ([]model.Media, error)
+	GetMedia(ctx context.Context, id int) (model.Media, error)
+	CreateMedia(ctx context.Context, media *model.Media) error
+	DeleteMedia(ctx context.Context, id int) error
+}
+
+type PostgresMediaRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresMediaRepo(pool *pgxpool.Pool) *PostgresMediaRepo {
+	return &PostgresMediaRepo{
+		pool: pool,
+	}
+}
+
+const mediaColumns = "id, owner, filename, content_type, size, checksum, width, height, storage_key, created_at"
+
+func scanMedia(row scanner, m *model.Media) error {
+	return row.Scan(&m.ID, &m.Owner, &m.Filename, &m.ContentType, &m.Size, &m.Checksum, &m.Width, &m.Height, &m.Key, &m.CreatedAt)
+}
+
+func (repo *PostgresMediaRepo) GetMediaByOwner(ctx context.Context, owner string) ([]model.Media, error) {
+	rows, err := repo.pool.Query(
+		ctx,
+		"SELECT "+mediaColumns+" FROM media WHERE owner = $1 ORDER BY created_at DESC, id DESC",
+		owner,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	media := []model.Media{}
+
+	for rows.Next() {
+		var m model.Media
+
+		err := scanMedia(rows, &m)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		media = append(media, m)
+	}
+
+	if rows.Err() != nil {
+		return nil, translateError(rows.Err())
+	}
+
+	ptrs := make([]*model.Media, len(media))
+	for i := range media {
+		ptrs[i] = &media[i]
+	}
+	if err := repo.loadVariants(ctx, ptrs...); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+func (repo *PostgresMediaRepo) GetMedia(ctx context.Context, id int) (model.Media, error) {
+	var m model.Media
+
+	err := scanMedia(repo.pool.QueryRow(
+		ctx,
+		"SELECT "+mediaColumns+" FROM media WHERE id = $1",
+		id,
+	), &m)
+
+	if err != nil {
+		return model.Media{}, translateError(err)
+	}
+
+	if err := repo.loadVariants(ctx, &m); err != nil {
+		return model.Media{}, err
+	}
+
+	return m, nil
+}
+
+// CreateMedia stores a file's record together with its variants.
+func (repo *PostgresMediaRepo) CreateMedia(ctx context.Context, media *model.Media) error {
+
+	tx, err := repo.pool.Begin(ctx)
+	if err != nil {
+		return translateError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO media (owner, filename, content_type, size, checksum, width, height, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`,
+		media.Owner, media.Filename, media.ContentType, media.Size, media.Checksum, media.Width, media.Height, media.Key,
+	).Scan(&media.ID, &media.CreatedAt)
+	if err != nil {
+		return translateError(err)
+	}
+
+	for _, v := range media.Variants {
+		_, err := tx.Exec(
+			ctx,
+			`INSERT INTO media_variants (media_id, name, width, height, content_type, size, storage_key)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			media.ID, v.Name, v.Width, v.Height, v.ContentType, v.Size, v.Key,
+		)
+		if err != nil {
+			return translateError(err)
+		}
+	}
+
+	return translateError(tx.Commit(ctx))
+}
+
+func (repo *PostgresMediaRepo) DeleteMedia(ctx context.Context, id int) error {
+	tag, err := repo.pool.Exec(
+		ctx,
+		"DELETE FROM media WHERE id = $1",
+		id,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// loadVariants fills in the variants of the given media with a single
+// query, smallest first.
+func (repo *PostgresMediaRepo) loadVariants(ctx context.Context, media ...*model.Media) error {
+	if len(media) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*model.Media, len(media))
+	ids := make([]int, 0, len(media))
+	for _, m := range media {
+		m.Variants = []model.MediaVariant{}
+		byID[m.ID] = m
+		ids = append(ids, m.ID)
+	}
+
+	rows, err := repo.pool.Query(
+		ctx,
+		`SELECT media_id, name, width, height, content_type, size, storage_key
+		FROM media_variants WHERE media_id = ANY($1) ORDER BY width`,
+		ids,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var v model.MediaVariant
+
+		err := rows.Scan(&id, &v.Name, &v.Width, &v.Height, &v.ContentType, &v.Size, &v.Key)
+		if err != nil {
+			return translateError(err)
+		}
+		byID[id].Variants = append(byID[id].Variants, v)
+	}
+
+	return translateError(rows.Err())
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/juanplagos/bubble/model"
+)
+
+type TagRepo interface {
+	GetAllTags(ctx context.Context) ([]model.Tag, error)
+}
+
+type PostgresTagRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresTagRepo(pool *pgxpool.Pool) *PostgresTagRepo {
+	return &PostgresTagRepo{
+		pool: pool,
+	}
+}
+
+// GetAllTags lists the tags in use by published entries, most used first.
+// Counts only include entries readers can see.
+func (repo *PostgresTagRepo) GetAllTags(ctx context.Context) ([]model.Tag, error) {
+	rows, err := repo.pool.Query(
+		ctx,
+		`SELECT t.name, COUNT(*) AS count
+		FROM tags t
+		JOIN entry_tags et ON et.tag_id = t.id
+		JOIN entries e ON e.id = et.entry_id
+		WHERE e.status = 'published' AND e.published_at <= NOW()
+		GROUP BY t.name
+		ORDER BY count DESC, t.name`,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	tags := []model.Tag{}
+
+	for rows.Next() {
+		var t model.Tag
+
+		err := rows.Scan(&t.Name, &t.Count)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		tags = append(tags, t)
+	}
+
+	if rows.Err() != nil {
+		return nil, translateError(rows.Err())
+	}
+
+	return tags, nil
+}
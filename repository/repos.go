@@ -0,0 +1,23 @@
+package repository
+
+import "github.com/jackc/pgx/v5/pgxpool"
+
+// Repos groups the repositories the usecases are built from, so the server
+// can be wired to Postgres or to the in-memory store alike.
+type Repos struct {
+	Entries  EntryRepo
+	Authors  AuthorRepo
+	Tags     TagRepo
+	Comments CommentRepo
+	Media    MediaRepo
+}
+
+func NewPostgresRepos(pool *pgxpool.Pool) Repos {
+	return Repos{
+		Entries:  NewPostgresEntryRepo(pool),
+		Authors:  NewPostgresAuthorRepo(pool),
+		Tags:     NewPostgresTagRepo(pool),
+		Comments: NewPostgresCommentRepo(pool),
+		Media:    NewPostgresMediaRepo(pool),
+	}
+}
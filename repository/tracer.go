@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// slowQuery is how long a query may run before it is logged as slow.
+	slowQuery = 500 * time.Millisecond
+	// maxLoggedSQL bounds how much of a statement ends up in the logs.
+	maxLoggedSQL = 500
+)
+
+// queryTracer logs queries with the context they ran under, so each line
+// carries the ID of the request that issued it. Failures the domain
+// expects, such as a taken slug, stay at debug level with everything else;
+// arguments are never logged since they may hold credentials.
+type queryTracer struct {
+	logger *slog.Logger
+}
+
+type queryTraceKey struct{}
+
+type queryTrace struct {
+	sql   string
+	start time.Time
+}
+
+func (t queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryTraceKey{}, queryTrace{sql: data.SQL, start: time.Now()})
+}
+
+func (t queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, _ := ctx.Value(queryTraceKey{}).(queryTrace)
+	elapsed := time.Since(trace.start)
+
+	attrs := []slog.Attr{
+		slog.String("sql", compactSQL(trace.sql)),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+
+	level, msg := slog.LevelDebug, "consulta"
+	if data.Err != nil {
+		attrs = append(attrs, slog.String("error", data.Err.Error()))
+		err := translateError(data.Err)
+		switch {
+		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+			level, msg = slog.LevelWarn, "consulta interrompida"
+		case !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrConflict) && !errors.Is(err, ErrInvalid):
+			level, msg = slog.LevelError, "falha na consulta"
+		}
+	} else if elapsed >= slowQuery {
+		level, msg = slog.LevelWarn, "consulta lenta"
+	}
+
+	t.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// compactSQL puts a statement on one line and cuts it short.
+func compactSQL(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > maxLoggedSQL {
+		sql = sql[:maxLoggedSQL] + "…"
+	}
+	return sql
+}
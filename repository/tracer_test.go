@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestQueryTracer(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		level string
+	}{
+		{"success", nil, "DEBUG"},
+		{"expected conflict", &pgconn.PgError{Code: "23505"}, "DEBUG"},
+		{"deadline", context.DeadlineExceeded, "WARN"},
+		{"unexpected failure", &pgconn.PgError{Code: "42P01", Message: "relation does not exist"}, "ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tracer := queryTracer{logger: slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+
+			ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT *\n\t\tFROM entries WHERE id = $1", Args: []any{"secret"}})
+			tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: tt.err})
+
+			var record map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("Expected a JSON record, got %q", buf.String())
+			}
+			if record["level"] != tt.level {
+				t.Errorf("Expected level %s, got %v", tt.level, record["level"])
+			}
+			if record["sql"] != "SELECT * FROM entries WHERE id = $1" {
+				t.Errorf("Expected compacted SQL, got %v", record["sql"])
+			}
+			if strings.Contains(buf.String(), "secret") {
+				t.Error("Expected arguments to stay out of the logs")
+			}
+		})
+	}
+}
@@ -1,38 +1,90 @@
 package router
 
 import (
+	"log/slog"
 	"net/http"
+	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/juanplagos/bubble/auth"
+	"github.com/juanplagos/bubble/event"
 	"github.com/juanplagos/bubble/handler"
+	"github.com/juanplagos/bubble/metrics"
 	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/storage"
 	"github.com/juanplagos/bubble/usecase"
 )
 
-func RegisterRoutes(pool *pgxpool.Pool) *http.ServeMux {
-	entryRepo := repository.NewPostgresEntryRepo(pool)
-	authorRepo := repository.NewPostgresAuthorRepo(pool)
+// RegisterRoutes wires every route. Requests get timeout to finish, except
+// uploads, which are bounded by their size instead: a large file over a
+// slow connection would otherwise never make it.
+func RegisterRoutes(repos repository.Repos, tokens *auth.TokenManager, events *event.Bus, feeds handler.FeedConfig, files storage.Storage, timeout time.Duration, logger *slog.Logger, reg *metrics.Registry, health *handler.HealthHandler) http.Handler {
+	entryUseCase := usecase.NewEntryUseCase(repos.Entries, events, logger)
+	authorUseCase := usecase.NewAuthorUseCase(repos.Authors, logger)
+	tagUseCase := usecase.NewTagUseCase(repos.Tags)
+	commentUseCase := usecase.NewCommentUseCase(repos.Comments, repos.Entries, repos.Authors, events, logger)
+	mediaUseCase := usecase.NewMediaUseCase(repos.Media, files, logger)
+	authUseCase := usecase.NewAuthUseCase(authorUseCase, tokens, events, logger)
 
-	entryUseCase := usecase.NewEntryUseCase(entryRepo)
-	authorUseCase := usecase.NewAuthorUseCase(authorRepo)
-
-	entryHandler := handler.NewEntryHandler(entryUseCase)
-	authorHandler := handler.NewAuthorHandler(authorUseCase)
+	entryHandler := handler.NewEntryHandler(entryUseCase, logger)
+	authorHandler := handler.NewAuthorHandler(authorUseCase, logger)
+	tagHandler := handler.NewTagHandler(tagUseCase, logger)
+	commentHandler := handler.NewCommentHandler(commentUseCase, logger)
+	mediaHandler := handler.NewMediaHandler(mediaUseCase, logger)
+	feedHandler := handler.NewFeedHandler(entryUseCase, authorUseCase, feeds, logger)
+	authHandler := handler.NewAuthHandler(authUseCase, logger)
+	requireAuth := authHandler.RequireAuth
+	optionalAuth := authHandler.OptionalAuth
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /entries/slug/", entryHandler.GetBySlug)
-	mux.HandleFunc("GET /entries", entryHandler.GetAll)
-	mux.HandleFunc("GET /entries/", entryHandler.GetByID)
-	mux.HandleFunc("POST /entries", entryHandler.Create)
-	mux.HandleFunc("PUT /entries/", entryHandler.Update)
-	mux.HandleFunc("DELETE /entries/", entryHandler.Delete)
+	mux.Handle("GET /metrics", reg)
+	mux.HandleFunc("GET /healthz", health.Live)
+	mux.HandleFunc("GET /readyz", health.Ready)
+	mux.HandleFunc("POST /auth/login", authHandler.Login)
+
+	mux.HandleFunc("GET /entries/slug/", optionalAuth(entryHandler.GetBySlug))
+	mux.HandleFunc("GET /entries/search", entryHandler.Search)
+	mux.HandleFunc("GET /entries", optionalAuth(entryHandler.GetAll))
+	mux.HandleFunc("GET /entries/", optionalAuth(entryHandler.GetByID))
+	mux.HandleFunc("POST /entries", requireAuth(entryHandler.Create))
+	mux.HandleFunc("PUT /entries/", requireAuth(entryHandler.Update))
+	mux.HandleFunc("DELETE /entries/", requireAuth(entryHandler.Delete))
+	// Revisions and comments live under their own prefixes: /entries/{id}/revisions
+	// would overlap with /entries/slug/ for an entry whose slug is "revisions".
+	mux.HandleFunc("GET /entries/revisions/{id}", requireAuth(entryHandler.GetRevisions))
+	mux.HandleFunc("GET /entries/revisions/{id}/diff", requireAuth(entryHandler.DiffRevisions))
+	mux.HandleFunc("POST /entries/revisions/{id}/{revision}/restore", requireAuth(entryHandler.RestoreRevision))
+
+	mux.HandleFunc("GET /entries/comments/{id}", optionalAuth(commentHandler.GetForEntry))
+	mux.HandleFunc("POST /entries/comments/{id}", optionalAuth(commentHandler.Create))
+	mux.HandleFunc("GET /comments", requireAuth(commentHandler.GetQueue))
+	mux.HandleFunc("POST /comments/{id}/approve", requireAuth(commentHandler.Approve))
+	mux.HandleFunc("POST /comments/{id}/reject", requireAuth(commentHandler.Reject))
+	mux.HandleFunc("DELETE /comments/{id}", requireAuth(commentHandler.Delete))
+
+	mux.HandleFunc("GET /media", requireAuth(mediaHandler.GetAll))
+	mux.HandleFunc("GET /media/{id}", mediaHandler.Serve)
+	mux.HandleFunc("GET /media/{id}/{variant}", mediaHandler.Serve)
+	mux.HandleFunc("DELETE /media/{id}", requireAuth(mediaHandler.Delete))
+
+	mux.HandleFunc("GET /tags", tagHandler.GetAll)
+
+	mux.HandleFunc("GET /feed.xml", feedHandler.Site)
+	mux.HandleFunc("GET /atom.xml", feedHandler.Site)
+	mux.HandleFunc("GET /feed.json", feedHandler.Site)
+	mux.HandleFunc("GET /tags/{tag}/feed.xml", feedHandler.Tag)
+	mux.HandleFunc("GET /tags/{tag}/atom.xml", feedHandler.Tag)
+	mux.HandleFunc("GET /tags/{tag}/feed.json", feedHandler.Tag)
 
 	mux.HandleFunc("GET /authors/email/", authorHandler.GetByEmail)
 	mux.HandleFunc("GET /authors", authorHandler.GetAll)
-	mux.HandleFunc("GET /authors/", authorHandler.GetByUsername)
-	mux.HandleFunc("POST /authors", authorHandler.Create)
-	mux.HandleFunc("PUT /authors/", authorHandler.Update)
-	mux.HandleFunc("DELETE /authors/", authorHandler.Delete)
+	mux.HandleFunc("GET /authors/", feedHandler.AuthorFeeds(authorHandler.GetByUsername))
+	mux.HandleFunc("POST /authors", requireAuth(authorHandler.Create))
+	mux.HandleFunc("PUT /authors/", requireAuth(authorHandler.Update))
+	mux.HandleFunc("DELETE /authors/", requireAuth(authorHandler.Delete))
+
+	root := http.NewServeMux()
+	root.HandleFunc("POST /media", requireAuth(mediaHandler.Upload))
+	root.Handle("/", handler.Deadline(timeout, handler.RecordRoute(mux)))
 
-	return mux
+	return handler.RecordRoute(root)
 }
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLocal(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := store.Put("abc", strings.NewReader("conteúdo")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	f, err := store.Open("abc")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "conteúdo" {
+		t.Errorf("Expected stored content, got %q", data)
+	}
+
+	if err := store.Put("abc", strings.NewReader("outro")); err == nil {
+		t.Error("Expected an existing key to be refused")
+	}
+
+	entries, _ := os.ReadDir(store.dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected temporary files to be cleaned up, got %v", entries)
+	}
+
+	if err := store.Delete("abc"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := store.Open("abc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLocal_InvalidKeys(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, key := range []string{"", "..", "../etc/passwd", `a\b`, ".hidden"} {
+		if err := store.Put(key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Expected ErrInvalidKey for %q, got %v", key, err)
+		}
+	}
+}
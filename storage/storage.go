@@ -0,0 +1,21 @@
+// Package storage keeps uploaded files. Backends address files by opaque
+// keys chosen by the caller and know nothing about their contents.
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	ErrNotFound   = errors.New("file not found")
+	ErrInvalidKey = errors.New("invalid storage key")
+)
+
+type Storage interface {
+	// Put stores everything read from r under key. Stored files are never
+	// overwritten; putting to a key that is taken fails.
+	Put(key string, r io.Reader) error
+	Open(key string) (io.ReadSeekCloser, error)
+	Delete(key string) error
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/juanplagos/bubble/auth"
+	"github.com/juanplagos/bubble/event"
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+)
+
+type authUseCase struct {
+	authors AuthorUseCase
+	tokens  *auth.TokenManager
+	events  *event.Bus
+	logger  *slog.Logger
+}
+
+func NewAuthUseCase(authors AuthorUseCase, tokens *auth.TokenManager, events *event.Bus, logger *slog.Logger) AuthUseCase {
+	return &authUseCase{
+		authors: authors,
+		tokens:  tokens,
+		events:  events,
+		logger:  logging.OrDiscard(logger),
+	}
+}
+
+func (au *authUseCase) Login(ctx context.Context, username, password string) (auth.Token, error) {
+	author, err := au.authors.VerifyCredentials(ctx, username, password)
+	if errors.Is(err, ErrInvalidCredentials) {
+		au.logger.WarnContext(ctx, "falha no login", "username", username)
+		au.events.Publish(event.LoginFailed, username)
+	}
+	if err != nil {
+		return auth.Token{}, err
+	}
+	return au.tokens.Issue(author.Username)
+}
+
+func (au *authUseCase) Authenticate(ctx context.Context, token string) (model.Author, error) {
+	claims, err := au.tokens.Parse(token)
+	if err != nil {
+		return model.Author{}, err
+	}
+
+	author, err := au.authors.GetAuthorByUsername(ctx, claims.Subject)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.Author{}, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return model.Author{}, err
+	}
+	return author, nil
+}
@@ -1,40 +1,133 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/juanplagos/bubble/logging"
 	"github.com/juanplagos/bubble/model"
 	"github.com/juanplagos/bubble/repository"
 )
 
 type authorUseCase struct {
-	repo repository.AuthorRepo
+	repo   repository.AuthorRepo
+	logger *slog.Logger
 }
 
-func NewAuthorUseCase(repo repository.AuthorRepo) AuthorUseCase {
+func NewAuthorUseCase(repo repository.AuthorRepo, logger *slog.Logger) AuthorUseCase {
 	return &authorUseCase{
-		repo: repo,
+		repo:   repo,
+		logger: logging.OrDiscard(logger),
 	}
 }
 
-func (au *authorUseCase) GetAllAuthors() ([]model.Author, error) {
-	return au.repo.GetAllAuthors()
+func (au *authorUseCase) GetAllAuthors(ctx context.Context) ([]model.Author, error) {
+	return au.repo.GetAllAuthors(ctx)
 }
 
-func (au *authorUseCase) GetAuthorByUsername(username string) (model.Author, error) {
-	return au.repo.GetAuthorByUsername(username)
+func (au *authorUseCase) GetAuthorByUsername(ctx context.Context, username string) (model.Author, error) {
+	return au.repo.GetAuthorByUsername(ctx, username)
 }
 
-func (au *authorUseCase) GetAuthorByEmail(email string) (model.Author, error) {
-	return au.repo.GetAuthorByEmail(email)
+func (au *authorUseCase) GetAuthorByEmail(ctx context.Context, email string) (model.Author, error) {
+	return au.repo.GetAuthorByEmail(ctx, email)
 }
 
-func (au *authorUseCase) CreateAuthor(author *model.Author) error {
-	return au.repo.CreateAuthor(author)
+func (au *authorUseCase) CreateAuthor(ctx context.Context, caller model.Author, author *model.Author) error {
+	if !Can(caller, PermManageAuthors) {
+		return ErrForbidden
+	}
+
+	if author.Role == "" {
+		author.Role = model.RoleWriter
+	}
+	if err := validateAuthor(author, true); err != nil {
+		return err
+	}
+
+	hash, err := hashPassword(author.Password)
+	if err != nil {
+		return err
+	}
+	author.Password = hash
+
+	if err := au.repo.CreateAuthor(ctx, author); err != nil {
+		return err
+	}
+	au.logger.InfoContext(ctx, "autor criado", "username", author.Username, "role", author.Role)
+	return nil
+}
+
+func (au *authorUseCase) UpdateAuthor(ctx context.Context, caller model.Author, username string, author *model.Author) error {
+	manager := Can(caller, PermManageAuthors)
+	if caller.Username != username && !manager {
+		return ErrForbidden
+	}
+
+	current, err := au.repo.GetAuthorByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	author.Username = current.Username
+
+	if author.Role == "" {
+		author.Role = current.Role
+	}
+	if author.Role != current.Role && !manager {
+		return ErrForbidden
+	}
+	if err := validateAuthor(author, false); err != nil {
+		return err
+	}
+
+	if author.Password == "" {
+		author.Password = current.Password
+	} else {
+		hash, err := hashPassword(author.Password)
+		if err != nil {
+			return err
+		}
+		author.Password = hash
+	}
+
+	return au.repo.UpdateAuthor(ctx, username, author)
 }
 
-func (au *authorUseCase) UpdateAuthor(username string, author *model.Author) error {
-	return au.repo.UpdateAuthor(username, author)
+func (au *authorUseCase) DeleteAuthor(ctx context.Context, caller model.Author, username string) error {
+	if !Can(caller, PermManageAuthors) {
+		return ErrForbidden
+	}
+	if err := au.repo.DeleteAuthor(ctx, username); err != nil {
+		return err
+	}
+	au.logger.InfoContext(ctx, "autor excluído", "username", username)
+	return nil
 }
 
-func (au *authorUseCase) DeleteAuthor(username string) error {
-	return au.repo.DeleteAuthor(username)
+func (au *authorUseCase) VerifyCredentials(ctx context.Context, username, password string) (model.Author, error) {
+	author, err := au.repo.GetAuthorByUsername(ctx, username)
+	if errors.Is(err, repository.ErrNotFound) {
+		checkPassword(unknownAuthorHash, password)
+		return model.Author{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return model.Author{}, err
+	}
+
+	ok, rehash := checkPassword(author.Password, password)
+	if !ok {
+		return model.Author{}, ErrInvalidCredentials
+	}
+
+	if rehash {
+		if hash, err := hashPassword(password); err == nil {
+			author.Password = hash
+			if err := au.repo.UpdateAuthor(ctx, author.Username, &author); err != nil {
+				au.logger.WarnContext(ctx, "não foi possível atualizar o hash da senha", "username", author.Username, "error", err)
+			}
+		}
+	}
+
+	return author, nil
 }
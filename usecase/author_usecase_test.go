@@ -0,0 +1,303 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type mockAuthorRepo struct {
+	author    model.Author
+	err       error
+	created   *model.Author
+	updated   *model.Author
+	updateErr error
+}
+
+func (m *mockAuthorRepo) GetAllAuthors(ctx context.Context) ([]model.Author, error) {
+	return []model.Author{m.author}, m.err
+}
+
+func (m *mockAuthorRepo) GetAuthorByUsername(ctx context.Context, username string) (model.Author, error) {
+	return m.author, m.err
+}
+
+func (m *mockAuthorRepo) GetAuthorByEmail(ctx context.Context, email string) (model.Author, error) {
+	return m.author, m.err
+}
+
+func (m *mockAuthorRepo) UsernameTaken(ctx context.Context, name string) (bool, error) {
+	return m.author.Username != "" && strings.EqualFold(m.author.Username, name), m.err
+}
+
+func (m *mockAuthorRepo) CreateAuthor(ctx context.Context, author *model.Author) error {
+	m.created = author
+	return m.err
+}
+
+func (m *mockAuthorRepo) UpdateAuthor(ctx context.Context, username string, author *model.Author) error {
+	m.updated = author
+	return m.updateErr
+}
+
+func (m *mockAuthorRepo) DeleteAuthor(ctx context.Context, username string) error {
+	return m.err
+}
+
+var admin = model.Author{Username: "admin", Role: model.RoleAdmin}
+
+func TestAuthorUseCase_CreateAuthor(t *testing.T) {
+	t.Run("hashes password", func(t *testing.T) {
+		repo := &mockAuthorRepo{}
+		uc := NewAuthorUseCase(repo, nil)
+
+		author := &model.Author{Username: "user1", Email: "user1@test.com", Password: "secret123"}
+		if err := uc.CreateAuthor(context.Background(), admin, author); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if repo.created.Password == "secret123" {
+			t.Fatal("Expected password to be hashed before storing")
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(repo.created.Password), []byte("secret123")); err != nil {
+			t.Errorf("Expected stored hash to match password, got %v", err)
+		}
+
+		if repo.created.Role != model.RoleWriter {
+			t.Errorf("Expected default role %q, got %q", model.RoleWriter, repo.created.Role)
+		}
+	})
+
+	t.Run("requires admin", func(t *testing.T) {
+		repo := &mockAuthorRepo{}
+		uc := NewAuthorUseCase(repo, nil)
+
+		editor := model.Author{Username: "editor", Role: model.RoleEditor}
+		author := &model.Author{Username: "user1", Email: "user1@test.com", Password: "secret"}
+
+		if err := uc.CreateAuthor(context.Background(), editor, author); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+
+		if repo.created != nil {
+			t.Error("Expected nothing to be stored")
+		}
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		repo := &mockAuthorRepo{}
+		uc := NewAuthorUseCase(repo, nil)
+
+		author := &model.Author{Username: "x", Email: "not-an-email", Password: "short"}
+
+		var validationErr *ValidationError
+		if err := uc.CreateAuthor(context.Background(), admin, author); !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+
+		fields := map[string]bool{}
+		for _, f := range validationErr.Fields {
+			fields[f.Field] = true
+		}
+		for _, want := range []string{"username", "email", "password"} {
+			if !fields[want] {
+				t.Errorf("Expected an error for %s, got %v", want, validationErr.Fields)
+			}
+		}
+
+		if repo.created != nil {
+			t.Error("Expected nothing to be stored")
+		}
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		uc := NewAuthorUseCase(&mockAuthorRepo{}, nil)
+
+		author := &model.Author{Username: "user1", Email: "user1@test.com", Password: "secret123", Role: "overlord"}
+
+		var validationErr *ValidationError
+		if err := uc.CreateAuthor(context.Background(), admin, author); !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+
+		if len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "role" {
+			t.Errorf("Expected a single role error, got %v", validationErr.Fields)
+		}
+	})
+}
+
+func TestAuthorUseCase_UpdateAuthor(t *testing.T) {
+	t.Run("hashes new password", func(t *testing.T) {
+		repo := &mockAuthorRepo{author: model.Author{Username: "user1", Role: model.RoleWriter}}
+		uc := NewAuthorUseCase(repo, nil)
+
+		self := model.Author{Username: "user1", Role: model.RoleWriter}
+		author := &model.Author{Email: "new@test.com", Password: "newsecret1"}
+		if err := uc.UpdateAuthor(context.Background(), self, "user1", author); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(repo.updated.Password), []byte("newsecret1")); err != nil {
+			t.Errorf("Expected stored hash to match password, got %v", err)
+		}
+	})
+
+	t.Run("keeps current password when omitted", func(t *testing.T) {
+		repo := &mockAuthorRepo{author: model.Author{Username: "user1", Password: "$2a$10$existinghash", Role: model.RoleWriter}}
+		uc := NewAuthorUseCase(repo, nil)
+
+		author := &model.Author{Email: "new@test.com"}
+		if err := uc.UpdateAuthor(context.Background(), admin, "user1", author); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if repo.updated.Password != "$2a$10$existinghash" {
+			t.Errorf("Expected current hash to be kept, got %s", repo.updated.Password)
+		}
+
+		if repo.updated.Role != model.RoleWriter {
+			t.Errorf("Expected current role to be kept, got %s", repo.updated.Role)
+		}
+	})
+
+	t.Run("other account", func(t *testing.T) {
+		repo := &mockAuthorRepo{author: model.Author{Username: "user1", Role: model.RoleWriter}}
+		uc := NewAuthorUseCase(repo, nil)
+
+		other := model.Author{Username: "user2", Role: model.RoleEditor}
+		author := &model.Author{Email: "new@test.com"}
+		if err := uc.UpdateAuthor(context.Background(), other, "user1", author); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+	})
+
+	t.Run("self promotion", func(t *testing.T) {
+		repo := &mockAuthorRepo{author: model.Author{Username: "user1", Role: model.RoleWriter}}
+		uc := NewAuthorUseCase(repo, nil)
+
+		self := model.Author{Username: "user1", Role: model.RoleWriter}
+		author := &model.Author{Email: "new@test.com", Role: model.RoleAdmin}
+		if err := uc.UpdateAuthor(context.Background(), self, "user1", author); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+	})
+
+	t.Run("admin changes role", func(t *testing.T) {
+		repo := &mockAuthorRepo{author: model.Author{Username: "user1", Role: model.RoleWriter}}
+		uc := NewAuthorUseCase(repo, nil)
+
+		author := &model.Author{Email: "new@test.com", Role: model.RoleEditor}
+		if err := uc.UpdateAuthor(context.Background(), admin, "user1", author); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if repo.updated.Role != model.RoleEditor {
+			t.Errorf("Expected role %q, got %q", model.RoleEditor, repo.updated.Role)
+		}
+	})
+}
+
+func TestAuthorUseCase_DeleteAuthor(t *testing.T) {
+	uc := NewAuthorUseCase(&mockAuthorRepo{}, nil)
+
+	writer := model.Author{Username: "user1", Role: model.RoleWriter}
+	if err := uc.DeleteAuthor(context.Background(), writer, "user1"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+
+	if err := uc.DeleteAuthor(context.Background(), admin, "user1"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestAuthorUseCase_VerifyCredentials(t *testing.T) {
+	t.Run("hashed password", func(t *testing.T) {
+		hash, _ := hashPassword("secret")
+		repo := &mockAuthorRepo{author: model.Author{Username: "user1", Password: hash}}
+		uc := NewAuthorUseCase(repo, nil)
+
+		author, err := uc.VerifyCredentials(context.Background(), "user1", "secret")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if author.Username != "user1" {
+			t.Errorf("Expected username 'user1', got %s", author.Username)
+		}
+
+		if repo.updated != nil {
+			t.Error("Expected current hash not to be rewritten")
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		hash, _ := hashPassword("secret")
+		repo := &mockAuthorRepo{author: model.Author{Username: "user1", Password: hash}}
+		uc := NewAuthorUseCase(repo, nil)
+
+		_, err := uc.VerifyCredentials(context.Background(), "user1", "wrong")
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+
+	t.Run("unknown author", func(t *testing.T) {
+		repo := &mockAuthorRepo{err: repository.ErrNotFound}
+		uc := NewAuthorUseCase(repo, nil)
+
+		_, err := uc.VerifyCredentials(context.Background(), "ghost", "secret")
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+		if cost, err := bcrypt.Cost([]byte(unknownAuthorHash)); err != nil || cost != passwordCost {
+			t.Errorf("Expected unknown usernames to cost a bcrypt comparison at cost %d, got %d (%v)", passwordCost, cost, err)
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		repo := &mockAuthorRepo{err: repository.ErrUnavailable}
+		uc := NewAuthorUseCase(repo, nil)
+
+		_, err := uc.VerifyCredentials(context.Background(), "user1", "secret")
+		if !errors.Is(err, repository.ErrUnavailable) {
+			t.Errorf("Expected ErrUnavailable, got %v", err)
+		}
+	})
+
+	t.Run("legacy plaintext password is rehashed", func(t *testing.T) {
+		repo := &mockAuthorRepo{author: model.Author{Username: "user1", Password: "secret"}}
+		uc := NewAuthorUseCase(repo, nil)
+
+		if _, err := uc.VerifyCredentials(context.Background(), "user1", "secret"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if repo.updated == nil {
+			t.Fatal("Expected legacy password to be rehashed")
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(repo.updated.Password), []byte("secret")); err != nil {
+			t.Errorf("Expected stored hash to match password, got %v", err)
+		}
+	})
+
+	t.Run("legacy plaintext mismatch", func(t *testing.T) {
+		repo := &mockAuthorRepo{author: model.Author{Username: "user1", Password: "secret"}}
+		uc := NewAuthorUseCase(repo, nil)
+
+		_, err := uc.VerifyCredentials(context.Background(), "user1", "other")
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+		}
+
+		if repo.updated != nil {
+			t.Error("Expected nothing to be rewritten")
+		}
+	})
+}
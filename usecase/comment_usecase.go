@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/juanplagos/bubble/event"
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+)
+
+// maxCommentLinks is how many links a comment may carry before it is held
+// for moderation regardless of who wrote it.
+const maxCommentLinks = 2
+
+type commentUseCase struct {
+	repo    repository.CommentRepo
+	entries repository.EntryRepo
+	authors repository.AuthorRepo
+	events  *event.Bus
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+func NewCommentUseCase(repo repository.CommentRepo, entries repository.EntryRepo, authors repository.AuthorRepo, events *event.Bus, logger *slog.Logger) CommentUseCase {
+	return &commentUseCase{
+		repo:    repo,
+		entries: entries,
+		authors: authors,
+		events:  events,
+		logger:  logging.OrDiscard(logger),
+		now:     time.Now,
+	}
+}
+
+// GetEntryComments returns the approved comments on an entry as threads.
+// Emails are only included for moderators.
+func (cu *commentUseCase) GetEntryComments(ctx context.Context, viewer model.Author, entryID int) ([]model.Comment, error) {
+	entry, err := cu.entries.GetEntryById(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.IsPublic(cu.now()) && !canEditEntry(viewer, entry) {
+		return nil, repository.ErrNotFound
+	}
+
+	comments, err := cu.repo.GetEntryComments(ctx, entryID, model.CommentApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	if !Can(viewer, PermModerate) {
+		for i := range comments {
+			comments[i].AuthorEmail = ""
+		}
+	}
+	return threadComments(comments), nil
+}
+
+// CreateComment accepts a comment on a published entry. Signed-in authors
+// comment under their account; everyone else gives a name and an email,
+// and may not pass for an author by taking their username. The comment's
+// status is decided here, never by the client.
+func (cu *commentUseCase) CreateComment(ctx context.Context, caller model.Author, entryID int, comment *model.Comment) error {
+	entry, err := cu.entries.GetEntryById(ctx, entryID)
+	if err != nil {
+		return err
+	}
+	if !entry.IsPublic(cu.now()) {
+		return repository.ErrNotFound
+	}
+
+	if caller.Username != "" {
+		comment.AuthorName, comment.AuthorEmail = caller.Username, caller.Email
+	}
+	comment.AuthorName = strings.TrimSpace(comment.AuthorName)
+	comment.AuthorEmail = strings.ToLower(strings.TrimSpace(comment.AuthorEmail))
+	comment.Body = strings.TrimSpace(comment.Body)
+	comment.EntryID = entryID
+	comment.Replies = nil
+
+	if err := validateComment(comment); err != nil {
+		return err
+	}
+	if caller.Username == "" {
+		if err := cu.checkGuestName(ctx, comment); err != nil {
+			return err
+		}
+	}
+	if err := cu.checkParent(ctx, comment); err != nil {
+		return err
+	}
+
+	comment.Status, err = cu.initialStatus(ctx, caller, entry, comment)
+	if err != nil {
+		return err
+	}
+
+	if err := cu.repo.CreateComment(ctx, comment); err != nil {
+		return err
+	}
+	cu.logger.InfoContext(ctx, "comentário recebido", "comment_id", comment.ID, "entry_id", entryID, "status", comment.Status)
+
+	cu.events.Publish(event.CommentCreated, *comment)
+	if comment.Status == model.CommentApproved {
+		cu.events.Publish(event.CommentApproved, *comment)
+	}
+	return nil
+}
+
+// checkGuestName keeps anonymous comments from using an author's username,
+// in any case, so readers can trust a comment signed with one.
+func (cu *commentUseCase) checkGuestName(ctx context.Context, comment *model.Comment) error {
+	taken, err := cu.authors.UsernameTaken(ctx, comment.AuthorName)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return &ValidationError{Fields: []FieldError{{
+			Field:   "author_name",
+			Message: "belongs to an author; sign in to comment under it",
+		}}}
+	}
+	return nil
+}
+
+// checkParent only allows replies to approved comments on the same entry,
+// so nobody can answer into a thread readers cannot see.
+func (cu *commentUseCase) checkParent(ctx context.Context, comment *model.Comment) error {
+	if comment.ParentID == nil {
+		return nil
+	}
+
+	parent, err := cu.repo.GetComment(ctx, *comment.ParentID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err != nil || parent.EntryID != comment.EntryID || parent.Status != model.CommentApproved {
+		return &ValidationError{Fields: []FieldError{{
+			Field:   "parent_id",
+			Message: "must be an approved comment on the same entry",
+		}}}
+	}
+	return nil
+}
+
+// initialStatus approves comments from the entry's editors and moderators,
+// and from emails that already had a comment approved, unless the comment
+// carries enough links to look like spam. Everything else waits in the
+// moderation queue.
+func (cu *commentUseCase) initialStatus(ctx context.Context, caller model.Author, entry model.Entry, comment *model.Comment) (model.CommentStatus, error) {
+	if caller.Username != "" && (canEditEntry(caller, entry) || Can(caller, PermModerate)) {
+		return model.CommentApproved, nil
+	}
+
+	if countLinks(comment.Body) > maxCommentLinks {
+		return model.CommentPending, nil
+	}
+
+	known, err := cu.repo.HasApprovedComment(ctx, comment.AuthorEmail)
+	if err != nil {
+		return "", err
+	}
+	if known {
+		return model.CommentApproved, nil
+	}
+	return model.CommentPending, nil
+}
+
+func (cu *commentUseCase) GetModerationQueue(ctx context.Context, caller model.Author, query model.CommentQuery) ([]model.Comment, error) {
+	if !Can(caller, PermModerate) {
+		return nil, ErrForbidden
+	}
+
+	query, err := normalizeCommentQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return cu.repo.GetComments(ctx, query)
+}
+
+func (cu *commentUseCase) ApproveComment(ctx context.Context, caller model.Author, id int) (model.Comment, error) {
+	comment, err := cu.setStatus(ctx, caller, id, model.CommentApproved)
+	if err != nil {
+		return model.Comment{}, err
+	}
+
+	cu.events.Publish(event.CommentApproved, comment)
+	return comment, nil
+}
+
+// RejectComment marks a comment as spam. It is kept rather than deleted so
+// it no longer counts toward auto-approving its author.
+func (cu *commentUseCase) RejectComment(ctx context.Context, caller model.Author, id int) (model.Comment, error) {
+	return cu.setStatus(ctx, caller, id, model.CommentSpam)
+}
+
+func (cu *commentUseCase) setStatus(ctx context.Context, caller model.Author, id int, status model.CommentStatus) (model.Comment, error) {
+	if !Can(caller, PermModerate) {
+		return model.Comment{}, ErrForbidden
+	}
+
+	comment, err := cu.repo.SetCommentStatus(ctx, id, status)
+	if err != nil {
+		return model.Comment{}, err
+	}
+	cu.logger.InfoContext(ctx, "comentário moderado", "comment_id", id, "status", status)
+	return comment, nil
+}
+
+func (cu *commentUseCase) DeleteComment(ctx context.Context, caller model.Author, id int) error {
+	if !Can(caller, PermModerate) {
+		return ErrForbidden
+	}
+	if err := cu.repo.DeleteComment(ctx, id); err != nil {
+		return err
+	}
+	cu.logger.InfoContext(ctx, "comentário excluído", "comment_id", id)
+	return nil
+}
+
+// threadComments nests replies under the comments they answer, keeping the
+// order comments were given in. Replies whose parent is missing, such as
+// one later marked as spam, are dropped with it.
+func threadComments(comments []model.Comment) []model.Comment {
+	children := make(map[int][]model.Comment)
+	for _, c := range comments {
+		parent := 0
+		if c.ParentID != nil {
+			parent = *c.ParentID
+		}
+		children[parent] = append(children[parent], c)
+	}
+
+	var attach func(parent int) []model.Comment
+	attach = func(parent int) []model.Comment {
+		replies := children[parent]
+		for i := range replies {
+			replies[i].Replies = attach(replies[i].ID)
+		}
+		return replies
+	}
+
+	threads := attach(0)
+	if threads == nil {
+		threads = []model.Comment{}
+	}
+	return threads
+}
+
+func countLinks(body string) int {
+	body = strings.ToLower(body)
+	return strings.Count(body, "http://") + strings.Count(body, "https://")
+}
@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+)
+
+type mockCommentRepo struct {
+	comments []model.Comment
+	created  *model.Comment
+	approved map[string]bool
+	status   model.CommentStatus
+	query    model.CommentQuery
+	err      error
+}
+
+func (m *mockCommentRepo) GetEntryComments(ctx context.Context, entryID int, status model.CommentStatus) ([]model.Comment, error) {
+	return m.comments, m.err
+}
+
+func (m *mockCommentRepo) GetComments(ctx context.Context, query model.CommentQuery) ([]model.Comment, error) {
+	m.query = query
+	return m.comments, m.err
+}
+
+func (m *mockCommentRepo) GetComment(ctx context.Context, id int) (model.Comment, error) {
+	for _, c := range m.comments {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return model.Comment{}, repository.ErrNotFound
+}
+
+func (m *mockCommentRepo) CreateComment(ctx context.Context, comment *model.Comment) error {
+	m.created = comment
+	return m.err
+}
+
+func (m *mockCommentRepo) SetCommentStatus(ctx context.Context, id int, status model.CommentStatus) (model.Comment, error) {
+	m.status = status
+	return model.Comment{ID: id, Status: status}, m.err
+}
+
+func (m *mockCommentRepo) DeleteComment(ctx context.Context, id int) error {
+	return m.err
+}
+
+func (m *mockCommentRepo) HasApprovedComment(ctx context.Context, email string) (bool, error) {
+	return m.approved[email], m.err
+}
+
+var moderator = model.Author{Username: "editor", Email: "editor@test.com", Role: model.RoleEditor}
+
+func publishedEntry() model.Entry {
+	published := time.Now().Add(-time.Hour)
+	return model.Entry{ID: 1, Author: "author", Status: model.StatusPublished, PublishedAt: &published}
+}
+
+func TestCommentUseCase_CreateComment(t *testing.T) {
+	reader := model.Comment{AuthorName: " Ana ", AuthorEmail: "Ana@Test.com", Body: "Ótimo texto"}
+
+	t.Run("new commenter waits for moderation", func(t *testing.T) {
+		repo := &mockCommentRepo{}
+		uc := NewCommentUseCase(repo, &mockEntryRepo{entry: publishedEntry()}, &mockAuthorRepo{}, nil, nil)
+
+		comment := reader
+		if err := uc.CreateComment(context.Background(), model.Author{}, 1, &comment); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if comment.Status != model.CommentPending || comment.AuthorName != "Ana" || comment.AuthorEmail != "ana@test.com" {
+			t.Errorf("Unexpected comment %+v", comment)
+		}
+	})
+
+	t.Run("previously approved email", func(t *testing.T) {
+		repo := &mockCommentRepo{approved: map[string]bool{"ana@test.com": true}}
+		uc := NewCommentUseCase(repo, &mockEntryRepo{entry: publishedEntry()}, &mockAuthorRepo{}, nil, nil)
+
+		comment := reader
+		if err := uc.CreateComment(context.Background(), model.Author{}, 1, &comment); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if comment.Status != model.CommentApproved {
+			t.Errorf("Expected approved, got %s", comment.Status)
+		}
+	})
+
+	t.Run("too many links", func(t *testing.T) {
+		repo := &mockCommentRepo{approved: map[string]bool{"ana@test.com": true}}
+		uc := NewCommentUseCase(repo, &mockEntryRepo{entry: publishedEntry()}, &mockAuthorRepo{}, nil, nil)
+
+		comment := reader
+		comment.Body = "http://a.example https://b.example HTTP://c.example"
+		if err := uc.CreateComment(context.Background(), model.Author{}, 1, &comment); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if comment.Status != model.CommentPending {
+			t.Errorf("Expected pending, got %s", comment.Status)
+		}
+	})
+
+	t.Run("entry author", func(t *testing.T) {
+		uc := NewCommentUseCase(&mockCommentRepo{}, &mockEntryRepo{entry: publishedEntry()}, &mockAuthorRepo{}, nil, nil)
+
+		comment := model.Comment{Body: "Obrigado!"}
+		if err := uc.CreateComment(context.Background(), model.Author{Username: "author", Email: "author@test.com", Role: model.RoleWriter}, 1, &comment); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if comment.Status != model.CommentApproved || comment.AuthorName != "author" {
+			t.Errorf("Unexpected comment %+v", comment)
+		}
+	})
+
+	t.Run("unpublished entry", func(t *testing.T) {
+		uc := NewCommentUseCase(&mockCommentRepo{}, &mockEntryRepo{entry: model.Entry{ID: 1, Status: model.StatusDraft}}, &mockAuthorRepo{}, nil, nil)
+
+		comment := reader
+		if err := uc.CreateComment(context.Background(), model.Author{}, 1, &comment); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("reply to hidden comment", func(t *testing.T) {
+		repo := &mockCommentRepo{comments: []model.Comment{{ID: 5, EntryID: 1, Status: model.CommentPending}}}
+		uc := NewCommentUseCase(repo, &mockEntryRepo{entry: publishedEntry()}, &mockAuthorRepo{}, nil, nil)
+
+		parent := 5
+		comment := reader
+		comment.ParentID = &parent
+
+		var validationErr *ValidationError
+		if err := uc.CreateComment(context.Background(), model.Author{}, 1, &comment); !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+
+		if validationErr.Fields[0].Field != "parent_id" || repo.created != nil {
+			t.Errorf("Unexpected fields %v", validationErr.Fields)
+		}
+	})
+
+	t.Run("author's name", func(t *testing.T) {
+		repo := &mockCommentRepo{}
+		uc := NewCommentUseCase(repo, &mockEntryRepo{entry: publishedEntry()}, &mockAuthorRepo{author: model.Author{Username: "ana"}}, nil, nil)
+
+		comment := reader
+
+		var validationErr *ValidationError
+		if err := uc.CreateComment(context.Background(), model.Author{}, 1, &comment); !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+
+		if validationErr.Fields[0].Field != "author_name" || repo.created != nil {
+			t.Errorf("Unexpected fields %v", validationErr.Fields)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		uc := NewCommentUseCase(&mockCommentRepo{}, &mockEntryRepo{entry: publishedEntry()}, &mockAuthorRepo{}, nil, nil)
+
+		comment := model.Comment{AuthorEmail: "not an email"}
+
+		var validationErr *ValidationError
+		if err := uc.CreateComment(context.Background(), model.Author{}, 1, &comment); !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+
+		if len(validationErr.Fields) != 3 {
+			t.Errorf("Expected 3 field errors, got %v", validationErr.Fields)
+		}
+	})
+}
+
+func TestCommentUseCase_GetEntryComments(t *testing.T) {
+	one, two, missing := 1, 2, 99
+	comments := []model.Comment{
+		{ID: 1, AuthorEmail: "a@test.com"},
+		{ID: 2, ParentID: &one, AuthorEmail: "b@test.com"},
+		{ID: 3},
+		{ID: 4, ParentID: &two},
+		{ID: 5, ParentID: &missing},
+	}
+
+	uc := NewCommentUseCase(&mockCommentRepo{comments: comments}, &mockEntryRepo{entry: publishedEntry()}, &mockAuthorRepo{}, nil, nil)
+
+	threads, err := uc.GetEntryComments(context.Background(), model.Author{}, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(threads) != 2 || threads[0].ID != 1 || threads[1].ID != 3 {
+		t.Fatalf("Expected two threads, got %+v", threads)
+	}
+
+	if len(threads[0].Replies) != 1 || len(threads[0].Replies[0].Replies) != 1 || threads[0].Replies[0].Replies[0].ID != 4 {
+		t.Errorf("Expected nested replies, got %+v", threads[0])
+	}
+
+	if threads[0].AuthorEmail != "" || threads[0].Replies[0].AuthorEmail != "" {
+		t.Error("Expected emails hidden from readers")
+	}
+}
+
+func TestCommentUseCase_Moderation(t *testing.T) {
+	t.Run("approve", func(t *testing.T) {
+		repo := &mockCommentRepo{}
+		uc := NewCommentUseCase(repo, &mockEntryRepo{}, &mockAuthorRepo{}, nil, nil)
+
+		comment, err := uc.ApproveComment(context.Background(), moderator, 3)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if comment.Status != model.CommentApproved {
+			t.Errorf("Expected approved, got %s", comment.Status)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		repo := &mockCommentRepo{}
+		uc := NewCommentUseCase(repo, &mockEntryRepo{}, &mockAuthorRepo{}, nil, nil)
+
+		if _, err := uc.RejectComment(context.Background(), moderator, 3); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if repo.status != model.CommentSpam {
+			t.Errorf("Expected spam, got %s", repo.status)
+		}
+	})
+
+	t.Run("queue defaults to pending", func(t *testing.T) {
+		repo := &mockCommentRepo{}
+		uc := NewCommentUseCase(repo, &mockEntryRepo{}, &mockAuthorRepo{}, nil, nil)
+
+		if _, err := uc.GetModerationQueue(context.Background(), moderator, model.CommentQuery{}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if repo.query.Status != model.CommentPending || repo.query.Limit != DefaultPageSize {
+			t.Errorf("Expected default query, got %+v", repo.query)
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		uc := NewCommentUseCase(&mockCommentRepo{}, &mockEntryRepo{}, &mockAuthorRepo{}, nil, nil)
+
+		if _, err := uc.ApproveComment(context.Background(), owner, 3); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+
+		if err := uc.DeleteComment(context.Background(), owner, 3); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+
+		if _, err := uc.GetModerationQueue(context.Background(), owner, model.CommentQuery{}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+	})
+}
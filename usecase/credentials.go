@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"crypto/subtle"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const passwordCost = bcrypt.DefaultCost
+
+// unknownAuthorHash is what a password is checked against when no author
+// has the username given, so that a failed login costs the same bcrypt
+// comparison whether or not the username exists. It must use passwordCost.
+const unknownAuthorHash = "$2a$10$Wz3Zt4af4y5XqhibALq1q.zBwVTPqCUEtHhuJWaqrVNw4E2MJFNDG"
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// checkPassword compares a login attempt against the stored credential.
+// Rows written before passwords were hashed still hold the plaintext, so
+// anything that is not a bcrypt hash is compared verbatim and flagged for
+// rehashing, as are hashes made with an outdated cost.
+func checkPassword(stored, password string) (ok bool, rehash bool) {
+	cost, err := bcrypt.Cost([]byte(stored))
+	if err != nil {
+		ok = subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return ok, ok
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) != nil {
+		return false, false
+	}
+	return true, cost != passwordCost
+}
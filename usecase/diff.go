@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+// maxDiffCells bounds the work spent matching the changed part of two
+// texts, counted as lines of one times lines of the other. Anything larger
+// is shown as the old block deleted and the new one inserted, so no body a
+// writer can save makes a diff slow.
+const maxDiffCells = 1 << 24
+
+// diffLines returns a line-level edit script turning a into b, built from
+// their longest common subsequence. Lines shared at both ends are matched
+// up front so only the part that changed is searched, in space linear in
+// its length.
+func diffLines(a, b string) []model.DiffLine {
+	x, y := splitLines(a), splitLines(b)
+
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]model.DiffLine, 0, len(x)+len(y))
+	for _, line := range x[:prefix] {
+		lines = append(lines, model.DiffLine{Op: model.DiffEqual, Text: line})
+	}
+
+	mx, my := x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]
+	if len(mx)*len(my) > maxDiffCells {
+		lines = appendLines(lines, model.DiffDelete, mx)
+		lines = appendLines(lines, model.DiffInsert, my)
+	} else {
+		d := &differ{x: mx, y: my, lines: lines}
+		d.ix, d.iy = d.intern(mx), d.intern(my)
+		d.diff(0, len(mx), 0, len(my))
+		lines = d.lines
+	}
+
+	for _, line := range x[len(x)-suffix:] {
+		lines = append(lines, model.DiffLine{Op: model.DiffEqual, Text: line})
+	}
+	return lines
+}
+
+// differ finds the longest common subsequence with Hirschberg's method:
+// the middle line of x is matched to the split of y that keeps the most
+// lines in common on both sides, and each half is solved on its own. Lines
+// are compared by interned ID rather than text.
+type differ struct {
+	x, y   []string
+	ix, iy []int
+	ids    map[string]int
+	lines  []model.DiffLine
+}
+
+func (d *differ) intern(lines []string) []int {
+	if d.ids == nil {
+		d.ids = make(map[string]int)
+	}
+	ids := make([]int, len(lines))
+	for i, line := range lines {
+		id, ok := d.ids[line]
+		if !ok {
+			id = len(d.ids)
+			d.ids[line] = id
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+// diff appends the edit script turning x[x0:x1] into y[y0:y1]. Deletions
+// come before insertions wherever both would do.
+func (d *differ) diff(x0, x1, y0, y1 int) {
+	switch {
+	case x0 == x1:
+		d.lines = appendLines(d.lines, model.DiffInsert, d.y[y0:y1])
+		return
+	case y0 == y1:
+		d.lines = appendLines(d.lines, model.DiffDelete, d.x[x0:x1])
+		return
+	case x1-x0 == 1:
+		for j := y0; j < y1; j++ {
+			if d.iy[j] == d.ix[x0] {
+				d.lines = appendLines(d.lines, model.DiffInsert, d.y[y0:j])
+				d.lines = append(d.lines, model.DiffLine{Op: model.DiffEqual, Text: d.x[x0]})
+				d.lines = appendLines(d.lines, model.DiffInsert, d.y[j+1:y1])
+				return
+			}
+		}
+		d.lines = appendLines(d.lines, model.DiffDelete, d.x[x0:x1])
+		d.lines = appendLines(d.lines, model.DiffInsert, d.y[y0:y1])
+		return
+	}
+
+	mid := (x0 + x1) / 2
+	forward := d.forward(x0, mid, y0, y1)
+	backward := d.backward(mid, x1, y0, y1)
+
+	split, best := y0, -1
+	for k := y0; k <= y1; k++ {
+		if n := forward[k-y0] + backward[k-y0]; n > best {
+			split, best = k, n
+		}
+	}
+
+	d.diff(x0, mid, y0, split)
+	d.diff(mid, x1, split, y1)
+}
+
+// forward returns, for each k in [y0, y1], the length of the longest common
+// subsequence of x[x0:x1] and y[y0:k].
+func (d *differ) forward(x0, x1, y0, y1 int) []int {
+	prev, cur := make([]int, y1-y0+1), make([]int, y1-y0+1)
+	for i := x0; i < x1; i++ {
+		for j := y0; j < y1; j++ {
+			if d.ix[i] == d.iy[j] {
+				cur[j-y0+1] = prev[j-y0] + 1
+			} else {
+				cur[j-y0+1] = max(prev[j-y0+1], cur[j-y0])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// backward returns, for each k in [y0, y1], the length of the longest
+// common subsequence of x[x0:x1] and y[k:y1].
+func (d *differ) backward(x0, x1, y0, y1 int) []int {
+	prev, cur := make([]int, y1-y0+1), make([]int, y1-y0+1)
+	for i := x1 - 1; i >= x0; i-- {
+		for j := y1 - 1; j >= y0; j-- {
+			if d.ix[i] == d.iy[j] {
+				cur[j-y0] = prev[j-y0+1] + 1
+			} else {
+				cur[j-y0] = max(prev[j-y0], cur[j-y0+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func appendLines(lines []model.DiffLine, op model.DiffOp, texts []string) []model.DiffLine {
+	for _, text := range texts {
+		lines = append(lines, model.DiffLine{Op: op, Text: text})
+	}
+	return lines
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
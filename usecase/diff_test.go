@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+func TestDiffLines(t *testing.T) {
+	eq := func(s string) model.DiffLine { return model.DiffLine{Op: model.DiffEqual, Text: s} }
+	ins := func(s string) model.DiffLine { return model.DiffLine{Op: model.DiffInsert, Text: s} }
+	del := func(s string) model.DiffLine { return model.DiffLine{Op: model.DiffDelete, Text: s} }
+
+	tests := []struct {
+		name string
+		a, b string
+		want []model.DiffLine
+	}{
+		{"identical", "a\nb", "a\nb", []model.DiffLine{eq("a"), eq("b")}},
+		{"both empty", "", "", []model.DiffLine{}},
+		{"from empty", "", "a\nb", []model.DiffLine{ins("a"), ins("b")}},
+		{"to empty", "a\nb", "", []model.DiffLine{del("a"), del("b")}},
+		{"changed line", "a\nb\nc", "a\nx\nc", []model.DiffLine{eq("a"), del("b"), ins("x"), eq("c")}},
+		{"insert in middle", "a\nc", "a\nb\nc", []model.DiffLine{eq("a"), ins("b"), eq("c")}},
+		{"moved line", "a\nb\nc\nd", "b\nc\na\nd", []model.DiffLine{del("a"), eq("b"), eq("c"), ins("a"), eq("d")}},
+		{"crlf and trailing newline", "a\r\nb\r\n", "a\nb", []model.DiffLine{eq("a"), eq("b")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffLines(tt.a, tt.b)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffLines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLines_LargeBodies(t *testing.T) {
+	body := func(prefix string, n int) string {
+		var b strings.Builder
+		for i := range n {
+			fmt.Fprintf(&b, "%s %d\n", prefix, i)
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		name     string
+		lines    int
+		maxBytes uint64
+	}{
+		// Small enough to be matched line by line.
+		{"searched", 3000, 8 << 20},
+		// Past maxDiffCells, shown as a block replaced by another.
+		{"too large to search", 50000, 32 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := body("old", tt.lines), body("new", tt.lines)
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			lines := diffLines(a, b)
+			runtime.ReadMemStats(&after)
+
+			if len(lines) != 2*tt.lines || lines[0].Op != model.DiffDelete || lines[len(lines)-1].Op != model.DiffInsert {
+				t.Errorf("Expected %d deletions followed by %d insertions, got %d lines", tt.lines, tt.lines, len(lines))
+			}
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > tt.maxBytes {
+				t.Errorf("Expected at most %d bytes allocated, got %d", tt.maxBytes, allocated)
+			}
+		})
+	}
+}
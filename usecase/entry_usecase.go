@@ -1,40 +1,328 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/juanplagos/bubble/event"
+	"github.com/juanplagos/bubble/logging"
 	"github.com/juanplagos/bubble/model"
 	"github.com/juanplagos/bubble/repository"
 )
 
 type entryUseCase struct {
-	repo repository.EntryRepo
+	repo   repository.EntryRepo
+	events *event.Bus
+	logger *slog.Logger
+	now    func() time.Time
 }
 
-func NewEntryUseCase(repo repository.EntryRepo) EntryUseCase {
+func NewEntryUseCase(repo repository.EntryRepo, events *event.Bus, logger *slog.Logger) EntryUseCase {
 	return &entryUseCase{
-		repo: repo,
+		repo:   repo,
+		events: events,
+		logger: logging.OrDiscard(logger),
+		now:    time.Now,
+	}
+}
+
+func (eu *entryUseCase) GetAllEntries(ctx context.Context, viewer model.Author, query model.EntryQuery) (model.EntryPage, error) {
+	query, err := normalizeEntryQuery(query)
+	if err != nil {
+		return model.EntryPage{}, err
+	}
+
+	if query.Status != model.StatusPublished {
+		switch {
+		case Can(viewer, PermEditAnyEntries):
+		case Can(viewer, PermEditOwnEntries) && (query.Author == "" || query.Author == viewer.Username):
+			query.Author = viewer.Username
+		default:
+			return model.EntryPage{}, ErrForbidden
+		}
+	}
+
+	return eu.repo.GetAllEntries(ctx, query)
+}
+
+func (eu *entryUseCase) GetEntryById(ctx context.Context, viewer model.Author, id int) (model.Entry, error) {
+	entry, err := eu.repo.GetEntryById(ctx, id)
+	if err != nil {
+		return model.Entry{}, err
+	}
+	return eu.visible(viewer, entry)
+}
+
+// GetEntryBySlug falls back to the slugs entries had before being renamed,
+// returning a MovedError with the current slug when one matches.
+func (eu *entryUseCase) GetEntryBySlug(ctx context.Context, viewer model.Author, slug string) (model.Entry, error) {
+	entry, err := eu.repo.GetEntryBySlug(ctx, slug)
+	if errors.Is(err, repository.ErrNotFound) {
+		return eu.movedEntry(ctx, viewer, slug)
+	}
+	if err != nil {
+		return model.Entry{}, err
 	}
+	return eu.visible(viewer, entry)
 }
 
-func (eu *entryUseCase) GetAllEntries() ([]model.Entry, error) {
-	return eu.repo.GetAllEntries()
+func (eu *entryUseCase) movedEntry(ctx context.Context, viewer model.Author, slug string) (model.Entry, error) {
+	entry, err := eu.repo.GetEntryByOldSlug(ctx, slug)
+	if err != nil {
+		return model.Entry{}, err
+	}
+	if _, err := eu.visible(viewer, entry); err != nil {
+		return model.Entry{}, err
+	}
+	return model.Entry{}, &MovedError{Slug: entry.Slug}
 }
 
-func (eu *entryUseCase) GetEntryById(id int) (model.Entry, error) {
-	return eu.repo.GetEntryById(id)
+// visible hides unpublished entries from everyone who could not edit them,
+// reporting them as missing rather than forbidden.
+func (eu *entryUseCase) visible(viewer model.Author, entry model.Entry) (model.Entry, error) {
+	if entry.IsPublic(eu.now()) || canEditEntry(viewer, entry) {
+		return entry, nil
+	}
+	return model.Entry{}, repository.ErrNotFound
 }
 
-func (eu *entryUseCase) GetEntryBySlug(slug string) (model.Entry, error) {
-	return eu.repo.GetEntryBySlug(slug)
+func (eu *entryUseCase) SearchEntries(ctx context.Context, query model.SearchQuery) ([]model.SearchResult, error) {
+	query, err := normalizeSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return eu.repo.SearchEntries(ctx, query)
 }
 
-func (eu *entryUseCase) CreateEntry(entry *model.Entry) error {
-	return eu.repo.CreateEntry(entry)
+func (eu *entryUseCase) CreateEntry(ctx context.Context, caller model.Author, entry *model.Entry) error {
+	if !Can(caller, PermCreateEntries) {
+		return ErrForbidden
+	}
+
+	if entry.Slug == "" {
+		slug, err := eu.generateSlug(ctx, entry.Title)
+		if err != nil {
+			return err
+		}
+		entry.Slug = slug
+	}
+
+	entry.Tags = normalizeTags(entry.Tags)
+	if entry.Tags == nil {
+		entry.Tags = []string{}
+	}
+	if entry.BodyFormat == "" {
+		entry.BodyFormat = model.FormatMarkdown
+	}
+
+	now := eu.now()
+	resolveStatus(entry, nil, now)
+	if err := validateEntry(entry, now); err != nil {
+		return err
+	}
+
+	entry.Author = caller.Username
+	if err := eu.repo.CreateEntry(ctx, entry); err != nil {
+		return err
+	}
+	eu.logger.InfoContext(ctx, "entrada criada", "entry_id", entry.ID, "slug", entry.Slug, "status", entry.Status)
+
+	eu.events.Publish(event.EntryCreated, *entry)
+	if entry.Status == model.StatusPublished {
+		eu.events.Publish(event.EntryPublished, *entry)
+	}
+	return nil
 }
 
-func (eu *entryUseCase) UpdateEntry(id int, entry *model.Entry) error {
-	return eu.repo.UpdateEntry(id, entry)
+func (eu *entryUseCase) UpdateEntry(ctx context.Context, caller model.Author, id int, entry *model.Entry) error {
+	current, err := eu.authorize(ctx, caller, id)
+	if err != nil {
+		return err
+	}
+
+	if entry.Slug == "" {
+		entry.Slug = current.Slug
+	}
+	// Leaving tags out keeps them; an empty list clears them.
+	entry.Tags = normalizeTags(entry.Tags)
+	if entry.Tags == nil {
+		entry.Tags = current.Tags
+	}
+	if entry.BodyFormat == "" {
+		entry.BodyFormat = current.BodyFormat
+	}
+
+	now := eu.now()
+	resolveStatus(entry, &current, now)
+	if err := validateEntry(entry, now); err != nil {
+		return err
+	}
+
+	entry.ID = id
+	entry.Author = current.Author
+	entry.CreatedAt = current.CreatedAt
+	if err := eu.repo.UpdateEntry(ctx, id, entry); err != nil {
+		return err
+	}
+	eu.logger.InfoContext(ctx, "entrada atualizada", "entry_id", id, "slug", entry.Slug, "status", entry.Status)
+
+	if entry.Status == model.StatusPublished && current.Status != model.StatusPublished {
+		eu.events.Publish(event.EntryPublished, *entry)
+	}
+	return nil
 }
 
-func (eu *entryUseCase) DeleteEntry(id int) error {
-	return eu.repo.DeleteEntry(id)
+// generateSlug derives a slug from title that no entry uses now or used
+// before a rename. An empty result is left for validation to reject.
+func (eu *entryUseCase) generateSlug(ctx context.Context, title string) (string, error) {
+	base := slugify(title)
+	if base == "" {
+		return "", nil
+	}
+
+	taken, err := eu.repo.SlugsWithPrefix(ctx, base)
+	if err != nil {
+		return "", err
+	}
+	return uniqueSlug(base, taken), nil
+}
+
+func (eu *entryUseCase) DeleteEntry(ctx context.Context, caller model.Author, id int) error {
+	if _, err := eu.authorize(ctx, caller, id); err != nil {
+		return err
+	}
+	if err := eu.repo.DeleteEntry(ctx, id); err != nil {
+		return err
+	}
+	eu.logger.InfoContext(ctx, "entrada excluída", "entry_id", id)
+	return nil
+}
+
+func (eu *entryUseCase) PublishScheduledEntries(ctx context.Context) ([]model.Entry, error) {
+	entries, err := eu.repo.PublishDueEntries(ctx, eu.now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		eu.logger.InfoContext(ctx, "entrada agendada publicada", "entry_id", e.ID, "slug", e.Slug)
+		eu.events.Publish(event.EntryPublished, e)
+	}
+	return entries, nil
+}
+
+// GetRevisions is limited to those allowed to edit the entry, since old
+// revisions may hold content that was never published.
+func (eu *entryUseCase) GetRevisions(ctx context.Context, caller model.Author, id int) ([]model.Revision, error) {
+	if _, err := eu.authorize(ctx, caller, id); err != nil {
+		return nil, err
+	}
+	return eu.repo.GetRevisions(ctx, id)
+}
+
+func (eu *entryUseCase) DiffRevisions(ctx context.Context, caller model.Author, id, from, to int) (model.RevisionDiff, error) {
+	current, err := eu.authorize(ctx, caller, id)
+	if err != nil {
+		return model.RevisionDiff{}, err
+	}
+
+	a, err := eu.revision(ctx, current, from)
+	if err != nil {
+		return model.RevisionDiff{}, err
+	}
+	b, err := eu.revision(ctx, current, to)
+	if err != nil {
+		return model.RevisionDiff{}, err
+	}
+
+	return model.RevisionDiff{
+		EntryID: id,
+		From:    from,
+		To:      to,
+		Title:   diffLines(a.Title, b.Title),
+		Slug:    diffLines(a.Slug, b.Slug),
+		Body:    diffLines(a.Body, b.Body),
+	}, nil
+}
+
+// RestoreRevision makes an old revision's content current again. It goes
+// through the regular update, so the content being replaced becomes a
+// revision of its own and the restore can itself be undone.
+func (eu *entryUseCase) RestoreRevision(ctx context.Context, caller model.Author, id, number int) (model.Entry, error) {
+	current, err := eu.authorize(ctx, caller, id)
+	if err != nil {
+		return model.Entry{}, err
+	}
+
+	rev, err := eu.repo.GetRevision(ctx, id, number)
+	if err != nil {
+		return model.Entry{}, err
+	}
+
+	entry := current
+	entry.Title, entry.Slug, entry.Body, entry.BodyFormat = rev.Title, rev.Slug, rev.Body, rev.BodyFormat
+	if err := validateEntry(&entry, eu.now()); err != nil {
+		return model.Entry{}, err
+	}
+
+	if err := eu.repo.UpdateEntry(ctx, id, &entry); err != nil {
+		return model.Entry{}, err
+	}
+	eu.logger.InfoContext(ctx, "revisão restaurada", "entry_id", id, "revision", number)
+	return entry, nil
+}
+
+// revision looks up a revision by number, treating 0 as the entry's
+// current content.
+func (eu *entryUseCase) revision(ctx context.Context, current model.Entry, number int) (model.Revision, error) {
+	if number == 0 {
+		return model.Revision{
+			EntryID:    current.ID,
+			Title:      current.Title,
+			Slug:       current.Slug,
+			Body:       current.Body,
+			BodyFormat: current.BodyFormat,
+		}, nil
+	}
+	return eu.repo.GetRevision(ctx, current.ID, number)
+}
+
+func (eu *entryUseCase) authorize(ctx context.Context, caller model.Author, id int) (model.Entry, error) {
+	entry, err := eu.repo.GetEntryById(ctx, id)
+	if err != nil {
+		return model.Entry{}, err
+	}
+
+	if !canEditEntry(caller, entry) {
+		return model.Entry{}, ErrForbidden
+	}
+	return entry, nil
+}
+
+// resolveStatus fills in the publication fields the client left out. On
+// update current is the stored entry, whose values are kept unless
+// overridden; on create it is nil and entries start out as drafts.
+func resolveStatus(entry *model.Entry, current *model.Entry, now time.Time) {
+	if entry.Status == "" {
+		entry.Status = model.StatusDraft
+		if current != nil {
+			entry.Status = current.Status
+		}
+	}
+
+	keep := current != nil && (entry.Status == current.Status || entry.Status == model.StatusArchived)
+	if entry.PublishedAt == nil && keep {
+		entry.PublishedAt = current.PublishedAt
+	}
+
+	switch entry.Status {
+	case model.StatusDraft:
+		entry.PublishedAt = nil
+	case model.StatusPublished:
+		if entry.PublishedAt == nil {
+			entry.PublishedAt = &now
+		}
+	}
 }
@@ -1,70 +1,134 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/juanplagos/bubble/event"
 	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
 )
 
 type mockEntryRepo struct {
+	results   []model.SearchResult
+	query     model.EntryQuery
 	entries   []model.Entry
 	entry     model.Entry
+	updated   *model.Entry
+	revisions []model.Revision
+	oldSlugs  map[string]model.Entry
+	slugs     []string
 	err       error
 	createErr error
 	updateErr error
 	deleteErr error
 }
 
-func (m *mockEntryRepo) GetAllEntries() ([]model.Entry, error) {
-	return m.entries, m.err
+func (m *mockEntryRepo) GetAllEntries(ctx context.Context, query model.EntryQuery) (model.EntryPage, error) {
+	m.query = query
+	return model.EntryPage{Entries: m.entries, Total: len(m.entries), Limit: query.Limit}, m.err
 }
 
-func (m *mockEntryRepo) GetEntryById(id int) (model.Entry, error) {
+func (m *mockEntryRepo) GetEntryById(ctx context.Context, id int) (model.Entry, error) {
 	return m.entry, m.err
 }
 
-func (m *mockEntryRepo) GetEntryBySlug(slug string) (model.Entry, error) {
+func (m *mockEntryRepo) GetEntryBySlug(ctx context.Context, slug string) (model.Entry, error) {
 	return m.entry, m.err
 }
 
-func (m *mockEntryRepo) CreateEntry(entry *model.Entry) error {
+func (m *mockEntryRepo) GetEntryByOldSlug(ctx context.Context, slug string) (model.Entry, error) {
+	if entry, ok := m.oldSlugs[slug]; ok {
+		return entry, nil
+	}
+	return model.Entry{}, repository.ErrNotFound
+}
+
+func (m *mockEntryRepo) SlugsWithPrefix(ctx context.Context, prefix string) ([]string, error) {
+	return m.slugs, m.err
+}
+
+func (m *mockEntryRepo) SearchEntries(ctx context.Context, query model.SearchQuery) ([]model.SearchResult, error) {
+	return m.results, m.err
+}
+
+func (m *mockEntryRepo) PublishDueEntries(ctx context.Context, now time.Time) ([]model.Entry, error) {
+	return m.entries, m.err
+}
+
+func (m *mockEntryRepo) CreateEntry(ctx context.Context, entry *model.Entry) error {
 	return m.createErr
 }
 
-func (m *mockEntryRepo) UpdateEntry(id int, entry *model.Entry) error {
+func (m *mockEntryRepo) UpdateEntry(ctx context.Context, id int, entry *model.Entry) error {
+	m.updated = entry
 	return m.updateErr
 }
 
-func (m *mockEntryRepo) DeleteEntry(id int) error {
+func (m *mockEntryRepo) DeleteEntry(ctx context.Context, id int) error {
 	return m.deleteErr
 }
 
+func (m *mockEntryRepo) GetRevisions(ctx context.Context, entryID int) ([]model.Revision, error) {
+	return m.revisions, m.err
+}
+
+func (m *mockEntryRepo) GetRevision(ctx context.Context, entryID, number int) (model.Revision, error) {
+	for _, r := range m.revisions {
+		if r.Number == number {
+			return r, nil
+		}
+	}
+	return model.Revision{}, repository.ErrNotFound
+}
+
+var owner = model.Author{Username: "author", Role: model.RoleWriter}
+
 func TestEntryUseCase_GetAllEntries(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		entries := []model.Entry{
 			{ID: 1, Title: "Test", Slug: "test", Body: "Body", Author: "author", CreatedAt: time.Now()},
 		}
 		repo := &mockEntryRepo{entries: entries}
-		uc := NewEntryUseCase(repo)
+		uc := NewEntryUseCase(repo, nil, nil)
 
-		result, err := uc.GetAllEntries()
+		result, err := uc.GetAllEntries(context.Background(), model.Author{}, model.EntryQuery{})
 
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
 
-		if len(result) != 1 {
-			t.Errorf("Expected 1 entry, got %d", len(result))
+		if len(result.Entries) != 1 {
+			t.Errorf("Expected 1 entry, got %d", len(result.Entries))
+		}
+
+		if repo.query.Limit != DefaultPageSize || repo.query.Sort != model.SortByCreatedAt || repo.query.Order != model.Descending {
+			t.Errorf("Expected default query, got %+v", repo.query)
+		}
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		_, err := uc.GetAllEntries(context.Background(), model.Author{}, model.EntryQuery{Limit: 1000, Sort: "views", Order: "up", Cursor: "abc", Offset: 10})
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+
+		if len(validationErr.Fields) != 4 {
+			t.Errorf("Expected 4 field errors, got %v", validationErr.Fields)
 		}
 	})
 
 	t.Run("error", func(t *testing.T) {
 		repo := &mockEntryRepo{err: errors.New("database error")}
-		uc := NewEntryUseCase(repo)
+		uc := NewEntryUseCase(repo, nil, nil)
 
-		_, err := uc.GetAllEntries()
+		_, err := uc.GetAllEntries(context.Background(), model.Author{}, model.EntryQuery{})
 
 		if err == nil {
 			t.Error("Expected error, got nil")
@@ -76,9 +140,9 @@ func TestEntryUseCase_GetEntryById(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		entry := model.Entry{ID: 1, Title: "Test", Slug: "test", Body: "Body", Author: "author", CreatedAt: time.Now()}
 		repo := &mockEntryRepo{entry: entry}
-		uc := NewEntryUseCase(repo)
+		uc := NewEntryUseCase(repo, nil, nil)
 
-		result, err := uc.GetEntryById(1)
+		result, err := uc.GetEntryById(context.Background(), owner, 1)
 
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
@@ -91,9 +155,9 @@ func TestEntryUseCase_GetEntryById(t *testing.T) {
 
 	t.Run("error", func(t *testing.T) {
 		repo := &mockEntryRepo{err: errors.New("not found")}
-		uc := NewEntryUseCase(repo)
+		uc := NewEntryUseCase(repo, nil, nil)
 
-		_, err := uc.GetEntryById(999)
+		_, err := uc.GetEntryById(context.Background(), owner, 999)
 
 		if err == nil {
 			t.Error("Expected error, got nil")
@@ -105,9 +169,9 @@ func TestEntryUseCase_GetEntryBySlug(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		entry := model.Entry{ID: 1, Title: "Test", Slug: "test", Body: "Body", Author: "author", CreatedAt: time.Now()}
 		repo := &mockEntryRepo{entry: entry}
-		uc := NewEntryUseCase(repo)
+		uc := NewEntryUseCase(repo, nil, nil)
 
-		result, err := uc.GetEntryBySlug("test")
+		result, err := uc.GetEntryBySlug(context.Background(), owner, "test")
 
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
@@ -122,24 +186,28 @@ func TestEntryUseCase_GetEntryBySlug(t *testing.T) {
 func TestEntryUseCase_CreateEntry(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		repo := &mockEntryRepo{}
-		uc := NewEntryUseCase(repo)
+		uc := NewEntryUseCase(repo, nil, nil)
 
-		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body", Author: "author"}
+		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body", Author: "someone-else"}
 
-		err := uc.CreateEntry(entry)
+		err := uc.CreateEntry(context.Background(), owner, entry)
 
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
+
+		if entry.Author != owner.Username {
+			t.Errorf("Expected author %q, got %q", owner.Username, entry.Author)
+		}
 	})
 
 	t.Run("error", func(t *testing.T) {
 		repo := &mockEntryRepo{createErr: errors.New("database error")}
-		uc := NewEntryUseCase(repo)
+		uc := NewEntryUseCase(repo, nil, nil)
 
 		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body", Author: "author"}
 
-		err := uc.CreateEntry(entry)
+		err := uc.CreateEntry(context.Background(), owner, entry)
 
 		if err == nil {
 			t.Error("Expected error, got nil")
@@ -149,12 +217,12 @@ func TestEntryUseCase_CreateEntry(t *testing.T) {
 
 func TestEntryUseCase_UpdateEntry(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		repo := &mockEntryRepo{}
-		uc := NewEntryUseCase(repo)
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Author: "author", BodyFormat: model.FormatMarkdown, Status: model.StatusDraft}}
+		uc := NewEntryUseCase(repo, nil, nil)
 
 		entry := &model.Entry{Title: "Updated", Slug: "updated", Body: "Body", Author: "author"}
 
-		err := uc.UpdateEntry(1, entry)
+		err := uc.UpdateEntry(context.Background(), owner, 1, entry)
 
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
@@ -162,25 +230,56 @@ func TestEntryUseCase_UpdateEntry(t *testing.T) {
 	})
 
 	t.Run("error", func(t *testing.T) {
-		repo := &mockEntryRepo{updateErr: errors.New("database error")}
-		uc := NewEntryUseCase(repo)
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Author: "author", BodyFormat: model.FormatMarkdown, Status: model.StatusDraft}, updateErr: errors.New("database error")}
+		uc := NewEntryUseCase(repo, nil, nil)
 
 		entry := &model.Entry{Title: "Updated", Slug: "updated", Body: "Body", Author: "author"}
 
-		err := uc.UpdateEntry(1, entry)
+		err := uc.UpdateEntry(context.Background(), owner, 1, entry)
 
 		if err == nil {
 			t.Error("Expected error, got nil")
 		}
 	})
+
+	t.Run("not owner", func(t *testing.T) {
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Author: "someone-else", BodyFormat: model.FormatMarkdown, Status: model.StatusDraft}}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		entry := &model.Entry{Title: "Updated", Slug: "updated", Body: "Body"}
+
+		err := uc.UpdateEntry(context.Background(), owner, 1, entry)
+
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+	})
+
+	t.Run("admin override", func(t *testing.T) {
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Author: "someone-else", BodyFormat: model.FormatMarkdown, Status: model.StatusDraft}}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		admin := model.Author{Username: "admin", Role: model.RoleAdmin}
+		entry := &model.Entry{Title: "Updated", Slug: "updated", Body: "Body"}
+
+		err := uc.UpdateEntry(context.Background(), admin, 1, entry)
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		if entry.Author != "someone-else" {
+			t.Errorf("Expected original author to be kept, got %q", entry.Author)
+		}
+	})
 }
 
 func TestEntryUseCase_DeleteEntry(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		repo := &mockEntryRepo{}
-		uc := NewEntryUseCase(repo)
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Author: "author", Status: model.StatusDraft}}
+		uc := NewEntryUseCase(repo, nil, nil)
 
-		err := uc.DeleteEntry(1)
+		err := uc.DeleteEntry(context.Background(), owner, 1)
 
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
@@ -188,14 +287,580 @@ func TestEntryUseCase_DeleteEntry(t *testing.T) {
 	})
 
 	t.Run("error", func(t *testing.T) {
-		repo := &mockEntryRepo{deleteErr: errors.New("database error")}
-		uc := NewEntryUseCase(repo)
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Author: "author", Status: model.StatusDraft}, deleteErr: errors.New("database error")}
+		uc := NewEntryUseCase(repo, nil, nil)
 
-		err := uc.DeleteEntry(1)
+		err := uc.DeleteEntry(context.Background(), owner, 1)
 
 		if err == nil {
 			t.Error("Expected error, got nil")
 		}
 	})
+
+	t.Run("not owner", func(t *testing.T) {
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Author: "someone-else", Status: model.StatusDraft}}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		err := uc.DeleteEntry(context.Background(), owner, 1)
+
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+	})
 }
 
+func TestEntryUseCase_Validation(t *testing.T) {
+	t.Run("missing fields", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		var validationErr *ValidationError
+		err := uc.CreateEntry(context.Background(), owner, &model.Entry{})
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+
+		if len(validationErr.Fields) != 3 {
+			t.Errorf("Expected 3 field errors, got %v", validationErr.Fields)
+		}
+	})
+
+	t.Run("invalid slug", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		entry := &model.Entry{Title: "Test", Slug: "Not A Slug!", Body: "Body"}
+
+		var validationErr *ValidationError
+		if err := uc.CreateEntry(context.Background(), owner, entry); !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+
+		if validationErr.Fields[0].Field != "slug" {
+			t.Errorf("Expected slug error, got %v", validationErr.Fields)
+		}
+	})
+}
+
+func TestEntryUseCase_SearchEntries(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := &mockEntryRepo{results: []model.SearchResult{{Entry: model.Entry{ID: 1}}}}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		results, err := uc.SearchEntries(context.Background(), model.SearchQuery{Text: "  gatos  "})
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		if len(results) != 1 {
+			t.Errorf("Expected 1 result, got %d", len(results))
+		}
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		_, err := uc.SearchEntries(context.Background(), model.SearchQuery{Text: "   "})
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("Expected ValidationError, got %v", err)
+		}
+	})
+}
+
+func TestEntryUseCase_Visibility(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		entry   model.Entry
+		viewer  model.Author
+		visible bool
+	}{
+		{"published to anonymous", model.Entry{Author: "author", Status: model.StatusPublished, PublishedAt: &past}, model.Author{}, true},
+		{"draft to anonymous", model.Entry{Author: "author", Status: model.StatusDraft}, model.Author{}, false},
+		{"scheduled to anonymous", model.Entry{Author: "author", Status: model.StatusScheduled, PublishedAt: &future}, model.Author{}, false},
+		{"published with future date", model.Entry{Author: "author", Status: model.StatusPublished, PublishedAt: &future}, model.Author{}, false},
+		{"draft to owner", model.Entry{Author: "author", Status: model.StatusDraft}, owner, true},
+		{"draft to another writer", model.Entry{Author: "someone-else", Status: model.StatusDraft}, owner, false},
+		{"draft to editor", model.Entry{Author: "someone-else", Status: model.StatusDraft}, model.Author{Username: "ed", Role: model.RoleEditor}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewEntryUseCase(&mockEntryRepo{entry: tt.entry}, nil, nil)
+
+			_, err := uc.GetEntryBySlug(context.Background(), tt.viewer, "test")
+
+			if tt.visible && err != nil {
+				t.Errorf("Expected entry to be visible, got %v", err)
+			}
+			if !tt.visible && !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEntryUseCase_ListUnpublished(t *testing.T) {
+	t.Run("anonymous", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		_, err := uc.GetAllEntries(context.Background(), model.Author{}, model.EntryQuery{Status: model.StatusDraft})
+
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+	})
+
+	t.Run("writer sees only own drafts", func(t *testing.T) {
+		repo := &mockEntryRepo{}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		if _, err := uc.GetAllEntries(context.Background(), owner, model.EntryQuery{Status: model.StatusDraft}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if repo.query.Author != owner.Username {
+			t.Errorf("Expected query restricted to %q, got %q", owner.Username, repo.query.Author)
+		}
+
+		_, err := uc.GetAllEntries(context.Background(), owner, model.EntryQuery{Status: model.StatusDraft, Author: "someone-else"})
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+	})
+}
+
+func TestEntryUseCase_Status(t *testing.T) {
+	t.Run("defaults to draft", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body"}
+		if err := uc.CreateEntry(context.Background(), owner, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if entry.Status != model.StatusDraft || entry.PublishedAt != nil {
+			t.Errorf("Expected unpublished draft, got %s %v", entry.Status, entry.PublishedAt)
+		}
+	})
+
+	t.Run("publishing sets the date and emits an event", func(t *testing.T) {
+		bus := event.NewBus()
+		var published []model.Entry
+		bus.Subscribe(event.EntryPublished, func(e event.Event) { published = append(published, e.Payload.(model.Entry)) })
+
+		uc := NewEntryUseCase(&mockEntryRepo{}, bus, nil)
+
+		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body", Status: model.StatusPublished}
+		if err := uc.CreateEntry(context.Background(), owner, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if entry.PublishedAt == nil {
+			t.Error("Expected published_at to be set")
+		}
+
+		if len(published) != 1 || published[0].Slug != "test" {
+			t.Errorf("Expected one published event, got %v", published)
+		}
+	})
+
+	t.Run("scheduled requires a future date", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		past := time.Now().Add(-time.Hour)
+		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body", Status: model.StatusScheduled, PublishedAt: &past}
+
+		var validationErr *ValidationError
+		if err := uc.CreateEntry(context.Background(), owner, entry); !errors.As(err, &validationErr) {
+			t.Errorf("Expected ValidationError, got %v", err)
+		}
+	})
+
+	t.Run("update keeps the current status", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Author: "author", BodyFormat: model.FormatMarkdown, Status: model.StatusPublished, PublishedAt: &past}}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		entry := &model.Entry{Title: "Updated", Slug: "updated", Body: "Body"}
+		if err := uc.UpdateEntry(context.Background(), owner, 1, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if entry.Status != model.StatusPublished || entry.PublishedAt == nil || !entry.PublishedAt.Equal(past) {
+			t.Errorf("Expected publication to be kept, got %s %v", entry.Status, entry.PublishedAt)
+		}
+	})
+}
+
+func TestEntryUseCase_PublishScheduledEntries(t *testing.T) {
+	bus := event.NewBus()
+	var count int
+	bus.Subscribe(event.EntryPublished, func(e event.Event) { count++ })
+
+	repo := &mockEntryRepo{entries: []model.Entry{{ID: 1}, {ID: 2}}}
+	uc := NewEntryUseCase(repo, bus, nil)
+
+	entries, err := uc.PublishScheduledEntries(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(entries) != 2 || count != 2 {
+		t.Errorf("Expected 2 entries and 2 events, got %d and %d", len(entries), count)
+	}
+}
+
+func TestEntryUseCase_Revisions(t *testing.T) {
+	current := model.Entry{ID: 1, Title: "Atual", Slug: "atual", Body: "a\nc", Author: "author", BodyFormat: model.FormatMarkdown, Status: model.StatusDraft}
+	revisions := []model.Revision{
+		{EntryID: 1, Number: 2, Title: "Segunda", Slug: "segunda", Body: "a\nb\nc", BodyFormat: model.FormatMarkdown},
+		{EntryID: 1, Number: 1, Title: "Primeira", Slug: "primeira", Body: "a", BodyFormat: model.FormatPlain},
+	}
+
+	t.Run("list", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{entry: current, revisions: revisions}, nil, nil)
+
+		result, err := uc.GetRevisions(context.Background(), owner, 1)
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(result) != 2 {
+			t.Errorf("Expected 2 revisions, got %d", len(result))
+		}
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		other := current
+		other.Author = "someone-else"
+		uc := NewEntryUseCase(&mockEntryRepo{entry: other, revisions: revisions}, nil, nil)
+
+		if _, err := uc.GetRevisions(context.Background(), owner, 1); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+
+		if _, err := uc.DiffRevisions(context.Background(), owner, 1, 1, 2); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+
+		if _, err := uc.RestoreRevision(context.Background(), owner, 1, 1); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+	})
+
+	t.Run("diff against current", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{entry: current, revisions: revisions}, nil, nil)
+
+		diff, err := uc.DiffRevisions(context.Background(), owner, 1, 2, 0)
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		want := []model.DiffLine{
+			{Op: model.DiffEqual, Text: "a"},
+			{Op: model.DiffDelete, Text: "b"},
+			{Op: model.DiffEqual, Text: "c"},
+		}
+		if len(diff.Body) != len(want) {
+			t.Fatalf("Expected body diff %v, got %v", want, diff.Body)
+		}
+		for i := range want {
+			if diff.Body[i] != want[i] {
+				t.Errorf("Expected body diff %v, got %v", want, diff.Body)
+				break
+			}
+		}
+
+		if len(diff.Title) != 2 || diff.Title[0].Op != model.DiffDelete || diff.Title[1].Op != model.DiffInsert {
+			t.Errorf("Expected title to be replaced, got %v", diff.Title)
+		}
+	})
+
+	t.Run("diff unknown revision", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{entry: current, revisions: revisions}, nil, nil)
+
+		if _, err := uc.DiffRevisions(context.Background(), owner, 1, 1, 7); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		repo := &mockEntryRepo{entry: current, revisions: revisions}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		entry, err := uc.RestoreRevision(context.Background(), owner, 1, 1)
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if repo.updated == nil || repo.updated.Title != "Primeira" || repo.updated.Body != "a" || repo.updated.BodyFormat != model.FormatPlain {
+			t.Errorf("Expected revision 1 to be written back, got %+v", repo.updated)
+		}
+
+		if entry.Author != current.Author || entry.Status != current.Status {
+			t.Errorf("Expected author and status to be kept, got %+v", entry)
+		}
+	})
+}
+
+func TestEntryUseCase_Slugs(t *testing.T) {
+	t.Run("generated from title", func(t *testing.T) {
+		repo := &mockEntryRepo{slugs: []string{"acao-e-reacao", "acao-e-reacao-2"}}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		entry := &model.Entry{Title: "Ação e reação", Body: "Body"}
+
+		if err := uc.CreateEntry(context.Background(), owner, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if entry.Slug != "acao-e-reacao-3" {
+			t.Errorf("Expected slug %q, got %q", "acao-e-reacao-3", entry.Slug)
+		}
+	})
+
+	t.Run("explicit slug kept", func(t *testing.T) {
+		repo := &mockEntryRepo{slugs: []string{"custom"}}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		entry := &model.Entry{Title: "Ação", Slug: "custom", Body: "Body"}
+
+		if err := uc.CreateEntry(context.Background(), owner, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if entry.Slug != "custom" {
+			t.Errorf("Expected slug %q, got %q", "custom", entry.Slug)
+		}
+	})
+
+	t.Run("kept on update", func(t *testing.T) {
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Slug: "original", Author: "author", BodyFormat: model.FormatMarkdown, Status: model.StatusDraft}}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		entry := &model.Entry{Title: "Outro título", Body: "Body"}
+
+		if err := uc.UpdateEntry(context.Background(), owner, 1, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if entry.Slug != "original" {
+			t.Errorf("Expected slug %q, got %q", "original", entry.Slug)
+		}
+	})
+
+	t.Run("renamed entry", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		repo := &mockEntryRepo{
+			err:      repository.ErrNotFound,
+			oldSlugs: map[string]model.Entry{"old": {ID: 1, Slug: "new", Author: "author", Status: model.StatusPublished, PublishedAt: &past}},
+		}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		_, err := uc.GetEntryBySlug(context.Background(), model.Author{}, "old")
+
+		var moved *MovedError
+		if !errors.As(err, &moved) {
+			t.Fatalf("Expected MovedError, got %v", err)
+		}
+
+		if moved.Slug != "new" {
+			t.Errorf("Expected slug %q, got %q", "new", moved.Slug)
+		}
+	})
+
+	t.Run("renamed draft stays hidden", func(t *testing.T) {
+		repo := &mockEntryRepo{
+			err:      repository.ErrNotFound,
+			oldSlugs: map[string]model.Entry{"old": {ID: 1, Slug: "new", Author: "author", Status: model.StatusDraft}},
+		}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		_, err := uc.GetEntryBySlug(context.Background(), model.Author{}, "old")
+
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestEntryUseCase_BodyFormat(t *testing.T) {
+	t.Run("defaults to markdown", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body"}
+		if err := uc.CreateEntry(context.Background(), owner, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if entry.BodyFormat != model.FormatMarkdown {
+			t.Errorf("Expected format %q, got %q", model.FormatMarkdown, entry.BodyFormat)
+		}
+	})
+
+	t.Run("kept on update", func(t *testing.T) {
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Author: "author", BodyFormat: model.FormatHTML, Status: model.StatusDraft}}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body"}
+		if err := uc.UpdateEntry(context.Background(), owner, 1, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if entry.BodyFormat != model.FormatHTML {
+			t.Errorf("Expected format %q, got %q", model.FormatHTML, entry.BodyFormat)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body", BodyFormat: "rtf"}
+
+		var validationErr *ValidationError
+		if err := uc.CreateEntry(context.Background(), owner, entry); !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+
+		if validationErr.Fields[0].Field != "body_format" {
+			t.Errorf("Expected body_format error, got %v", validationErr.Fields)
+		}
+	})
+}
+
+func TestEntryUseCase_Tags(t *testing.T) {
+	t.Run("normalized on create", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body", Tags: []string{"Go", "Programação", "go", " SQL "}}
+
+		if err := uc.CreateEntry(context.Background(), owner, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		want := []string{"go", "programacao", "sql"}
+		if len(entry.Tags) != len(want) {
+			t.Fatalf("Expected tags %v, got %v", want, entry.Tags)
+		}
+		for i := range want {
+			if entry.Tags[i] != want[i] {
+				t.Errorf("Expected tags %v, got %v", want, entry.Tags)
+				break
+			}
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		uc := NewEntryUseCase(&mockEntryRepo{}, nil, nil)
+
+		entry := &model.Entry{Title: "Test", Slug: "test", Body: "Body", Tags: []string{"ok", "!!!"}}
+
+		var validationErr *ValidationError
+		if err := uc.CreateEntry(context.Background(), owner, entry); !errors.As(err, &validationErr) {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+
+		if validationErr.Fields[0].Field != "tags" {
+			t.Errorf("Expected tags error, got %v", validationErr.Fields)
+		}
+	})
+
+	t.Run("kept on update when omitted", func(t *testing.T) {
+		repo := &mockEntryRepo{entry: model.Entry{ID: 1, Author: "author", BodyFormat: model.FormatMarkdown, Status: model.StatusDraft, Tags: []string{"go"}}}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		entry := &model.Entry{Title: "Updated", Slug: "updated", Body: "Body"}
+		if err := uc.UpdateEntry(context.Background(), owner, 1, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(entry.Tags) != 1 || entry.Tags[0] != "go" {
+			t.Errorf("Expected tags to be kept, got %v", entry.Tags)
+		}
+
+		entry = &model.Entry{Title: "Updated", Slug: "updated", Body: "Body", Tags: []string{}}
+		if err := uc.UpdateEntry(context.Background(), owner, 1, entry); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(entry.Tags) != 0 {
+			t.Errorf("Expected tags to be cleared, got %v", entry.Tags)
+		}
+	})
+
+	t.Run("query defaults to matching all tags", func(t *testing.T) {
+		repo := &mockEntryRepo{}
+		uc := NewEntryUseCase(repo, nil, nil)
+
+		if _, err := uc.GetAllEntries(context.Background(), model.Author{}, model.EntryQuery{Tags: []string{"Go", "go"}}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(repo.query.Tags) != 1 || repo.query.TagMatch != model.MatchAllTags {
+			t.Errorf("Expected one tag matched with all, got %v %q", repo.query.Tags, repo.query.TagMatch)
+		}
+	})
+}
+
+// TestEntryUseCase_MemoryRepo runs the usecase against a real repository,
+// so slug generation, lookups and updates are checked end to end.
+func TestEntryUseCase_MemoryRepo(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore(nil)
+	author := model.Author{Username: "author", Email: "author@example.com", Role: model.RoleWriter}
+	if err := repository.NewMemoryAuthorRepo(store).CreateAuthor(ctx, &author); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	uc := NewEntryUseCase(repository.NewMemoryEntryRepo(store), nil, nil)
+
+	first := &model.Entry{Title: "Olá mundo", Body: "Body", Status: model.StatusPublished}
+	second := &model.Entry{Title: "Olá mundo", Body: "Body", Status: model.StatusPublished}
+	for _, e := range []*model.Entry{first, second} {
+		if err := uc.CreateEntry(ctx, owner, e); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if first.Slug != "ola-mundo" || second.Slug != "ola-mundo-2" {
+		t.Errorf("Expected distinct slugs, got %q and %q", first.Slug, second.Slug)
+	}
+
+	got, err := uc.GetEntryBySlug(ctx, model.Author{}, "ola-mundo-2")
+	if err != nil || got.ID != second.ID {
+		t.Fatalf("Expected entry %d, got %+v (%v)", second.ID, got, err)
+	}
+
+	update := &model.Entry{Title: "Adeus", Slug: "adeus", Body: "Other body"}
+	if err := uc.UpdateEntry(ctx, owner, first.ID, update); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	got, err = uc.GetEntryById(ctx, model.Author{}, first.ID)
+	if err != nil || got.Title != "Adeus" || got.Body != "Other body" || got.Status != model.StatusPublished {
+		t.Errorf("Expected the update to be stored, got %+v (%v)", got, err)
+	}
+
+	_, err = uc.GetEntryBySlug(ctx, model.Author{}, "ola-mundo")
+	var moved *MovedError
+	if !errors.As(err, &moved) || moved.Slug != "adeus" {
+		t.Errorf("Expected a redirect to %q, got %v", "adeus", err)
+	}
+
+	revisions, err := uc.GetRevisions(ctx, owner, first.ID)
+	if err != nil || len(revisions) != 1 || revisions[0].Title != "Olá mundo" {
+		t.Errorf("Expected the original as a revision, got %+v (%v)", revisions, err)
+	}
+
+	if err := uc.DeleteEntry(ctx, owner, second.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := uc.GetEntryById(ctx, model.Author{}, second.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrForbidden          = errors.New("forbidden")
+	ErrTooLarge           = errors.New("file too large")
+	ErrUnsupportedMedia   = errors.New("unsupported media type")
+)
+
+// MovedError reports that an entry was looked up by a slug it no longer
+// uses. Slug is the one it has now.
+type MovedError struct {
+	Slug string
+}
+
+func (e *MovedError) Error() string {
+	return "entry moved to " + e.Slug
+}
@@ -1,21 +1,58 @@
 package usecase
 
-import "github.com/juanplagos/bubble/model"
+import (
+	"context"
+	"io"
+
+	"github.com/juanplagos/bubble/auth"
+	"github.com/juanplagos/bubble/model"
+)
 
 type EntryUseCase interface {
-	GetAllEntries() ([]model.Entry, error)
-	GetEntryById(id int) (model.Entry, error)
-	GetEntryBySlug(slug string) (model.Entry, error)
-	CreateEntry(entry *model.Entry) error
-	UpdateEntry(id int, entry *model.Entry) error
-	DeleteEntry(id int) error
+	GetAllEntries(ctx context.Context, viewer model.Author, query model.EntryQuery) (model.EntryPage, error)
+	GetEntryById(ctx context.Context, viewer model.Author, id int) (model.Entry, error)
+	GetEntryBySlug(ctx context.Context, viewer model.Author, slug string) (model.Entry, error)
+	SearchEntries(ctx context.Context, query model.SearchQuery) ([]model.SearchResult, error)
+	CreateEntry(ctx context.Context, caller model.Author, entry *model.Entry) error
+	UpdateEntry(ctx context.Context, caller model.Author, id int, entry *model.Entry) error
+	DeleteEntry(ctx context.Context, caller model.Author, id int) error
+	PublishScheduledEntries(ctx context.Context) ([]model.Entry, error)
+	GetRevisions(ctx context.Context, caller model.Author, id int) ([]model.Revision, error)
+	DiffRevisions(ctx context.Context, caller model.Author, id, from, to int) (model.RevisionDiff, error)
+	RestoreRevision(ctx context.Context, caller model.Author, id, number int) (model.Entry, error)
+}
+
+type CommentUseCase interface {
+	GetEntryComments(ctx context.Context, viewer model.Author, entryID int) ([]model.Comment, error)
+	CreateComment(ctx context.Context, caller model.Author, entryID int, comment *model.Comment) error
+	GetModerationQueue(ctx context.Context, caller model.Author, query model.CommentQuery) ([]model.Comment, error)
+	ApproveComment(ctx context.Context, caller model.Author, id int) (model.Comment, error)
+	RejectComment(ctx context.Context, caller model.Author, id int) (model.Comment, error)
+	DeleteComment(ctx context.Context, caller model.Author, id int) error
+}
+
+type MediaUseCase interface {
+	Upload(ctx context.Context, caller model.Author, filename string, r io.Reader) (model.Media, error)
+	GetMedia(ctx context.Context, id int, variant string) (model.Media, io.ReadSeekCloser, error)
+	ListMedia(ctx context.Context, caller model.Author) ([]model.Media, error)
+	DeleteMedia(ctx context.Context, caller model.Author, id int) error
+}
+
+type TagUseCase interface {
+	GetAllTags(ctx context.Context) ([]model.Tag, error)
 }
 
 type AuthorUseCase interface {
-	GetAllAuthors() ([]model.Author, error)
-	GetAuthorByUsername(username string) (model.Author, error)
-	GetAuthorByEmail(email string) (model.Author, error)
-	CreateAuthor(author *model.Author) error
-	UpdateAuthor(username string, author *model.Author) error
-	DeleteAuthor(username string) error
+	GetAllAuthors(ctx context.Context) ([]model.Author, error)
+	GetAuthorByUsername(ctx context.Context, username string) (model.Author, error)
+	GetAuthorByEmail(ctx context.Context, email string) (model.Author, error)
+	CreateAuthor(ctx context.Context, caller model.Author, author *model.Author) error
+	UpdateAuthor(ctx context.Context, caller model.Author, username string, author *model.Author) error
+	DeleteAuthor(ctx context.Context, caller model.Author, username string) error
+	VerifyCredentials(ctx context.Context, username, password string) (model.Author, error)
+}
+
+type AuthUseCase interface {
+	Login(ctx context.Context, username, password string) (auth.Token, error)
+	Authenticate(ctx context.Context, token string) (model.Author, error)
 }
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/juanplagos/bubble/imaging"
+	"github.com/juanplagos/bubble/logging"
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/storage"
+)
+
+const (
+	MaxMediaSize      = 10 << 20
+	maxFilenameLength = 255
+	// sniffLength is how much of a file http.DetectContentType looks at.
+	sniffLength = 512
+)
+
+// mediaTypes lists what may be uploaded. Types are sniffed from the
+// content, never taken from the client, and exclude anything a browser
+// would run as a document (HTML, SVG) when served from our origin.
+var mediaTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+type mediaUseCase struct {
+	repo    repository.MediaRepo
+	storage storage.Storage
+	logger  *slog.Logger
+}
+
+func NewMediaUseCase(repo repository.MediaRepo, storage storage.Storage, logger *slog.Logger) MediaUseCase {
+	return &mediaUseCase{
+		repo:    repo,
+		storage: storage,
+		logger:  logging.OrDiscard(logger),
+	}
+}
+
+// Upload streams r into storage while hashing and measuring it, so files
+// such as PDFs are never held in memory whole. Images are the exception:
+// they are read completely to strip their metadata and derive variants,
+// which are stored next to the original under the same key plus their
+// name. Anything over MaxMediaSize is reported as ErrTooLarge.
+func (mu *mediaUseCase) Upload(ctx context.Context, caller model.Author, filename string, r io.Reader) (model.Media, error) {
+	if !Can(caller, PermCreateEntries) {
+		return model.Media{}, ErrForbidden
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return model.Media{}, err
+	}
+	if n == 0 {
+		return model.Media{}, &ValidationError{Fields: []FieldError{{Field: "file", Message: "must not be empty"}}}
+	}
+	head = head[:n]
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !mediaTypes[contentType] {
+		return model.Media{}, fmt.Errorf("%w: %s", ErrUnsupportedMedia, contentType)
+	}
+
+	var body io.Reader = io.LimitReader(io.MultiReader(bytes.NewReader(head), r), MaxMediaSize+1)
+	var processed imaging.Result
+	if imaging.Supported(contentType) {
+		if processed, err = processImage(body, contentType); err != nil {
+			return model.Media{}, err
+		}
+		body = bytes.NewReader(processed.Original)
+	}
+
+	key, err := newMediaKey()
+	if err != nil {
+		return model.Media{}, err
+	}
+
+	hash := sha256.New()
+	size := &byteCounter{}
+	if err := mu.storage.Put(key, io.TeeReader(body, io.MultiWriter(hash, size))); err != nil {
+		return model.Media{}, err
+	}
+
+	media := model.Media{
+		Owner:       caller.Username,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Size:        size.n,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		Width:       processed.Width,
+		Height:      processed.Height,
+		Key:         key,
+		Variants:    []model.MediaVariant{},
+	}
+	if media.Size > MaxMediaSize {
+		mu.removeFiles(ctx, media)
+		return model.Media{}, ErrTooLarge
+	}
+
+	for _, v := range processed.Variants {
+		variant := model.MediaVariant{
+			Name:        v.Name,
+			Width:       v.Width,
+			Height:      v.Height,
+			ContentType: v.ContentType,
+			Size:        int64(len(v.Data)),
+			Key:         key + "-" + v.Name,
+		}
+		if err := mu.storage.Put(variant.Key, bytes.NewReader(v.Data)); err != nil {
+			mu.removeFiles(ctx, media)
+			return model.Media{}, err
+		}
+		media.Variants = append(media.Variants, variant)
+	}
+
+	if err := mu.repo.CreateMedia(ctx, &media); err != nil {
+		mu.removeFiles(ctx, media)
+		return model.Media{}, err
+	}
+	mu.logger.InfoContext(ctx, "arquivo enviado", "media_id", media.ID, "content_type", media.ContentType, "size", media.Size)
+	return media, nil
+}
+
+func processImage(r io.Reader, contentType string) (imaging.Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return imaging.Result{}, err
+	}
+	if len(data) > MaxMediaSize {
+		return imaging.Result{}, ErrTooLarge
+	}
+
+	result, err := imaging.Process(data, contentType, imaging.Sizes)
+	switch {
+	case errors.Is(err, imaging.ErrTooLarge):
+		return imaging.Result{}, fmt.Errorf("%w: images may have at most %d pixels", ErrTooLarge, imaging.MaxPixels)
+	case errors.Is(err, imaging.ErrInvalid):
+		return imaging.Result{}, &ValidationError{Fields: []FieldError{{Field: "file", Message: "must be a valid image"}}}
+	}
+	return result, err
+}
+
+// GetMedia is public: uploads are meant to be referenced from entries.
+// With a variant name the returned media describes that variant's file
+// instead of the original. The caller must close the returned file.
+func (mu *mediaUseCase) GetMedia(ctx context.Context, id int, variant string) (model.Media, io.ReadSeekCloser, error) {
+	media, err := mu.repo.GetMedia(ctx, id)
+	if err != nil {
+		return model.Media{}, nil, err
+	}
+
+	if variant != "" {
+		i := slices.IndexFunc(media.Variants, func(v model.MediaVariant) bool { return v.Name == variant })
+		if i < 0 {
+			return model.Media{}, nil, repository.ErrNotFound
+		}
+		v := media.Variants[i]
+		media.ContentType, media.Size, media.Width, media.Height, media.Key = v.ContentType, v.Size, v.Width, v.Height, v.Key
+	}
+
+	f, err := mu.storage.Open(media.Key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return model.Media{}, nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return model.Media{}, nil, err
+	}
+	return media, f, nil
+}
+
+func (mu *mediaUseCase) ListMedia(ctx context.Context, caller model.Author) ([]model.Media, error) {
+	return mu.repo.GetMediaByOwner(ctx, caller.Username)
+}
+
+// DeleteMedia removes the record before the file, so a failure in between
+// leaves an unreferenced file rather than a record pointing at nothing.
+func (mu *mediaUseCase) DeleteMedia(ctx context.Context, caller model.Author, id int) error {
+	media, err := mu.repo.GetMedia(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !canManageMedia(caller, media) {
+		return ErrForbidden
+	}
+
+	if err := mu.repo.DeleteMedia(ctx, id); err != nil {
+		return err
+	}
+	mu.logger.InfoContext(ctx, "arquivo excluído", "media_id", id)
+	return mu.removeFiles(ctx, media)
+}
+
+// removeFiles deletes the original and every variant of media from
+// storage, carrying on past failures and reporting the first. Files left
+// behind are logged, since callers cleaning up after a failed upload have
+// another error to report.
+func (mu *mediaUseCase) removeFiles(ctx context.Context, media model.Media) error {
+	keys := []string{media.Key}
+	for _, v := range media.Variants {
+		keys = append(keys, v.Key)
+	}
+
+	var first error
+	for _, key := range keys {
+		err := mu.storage.Delete(key)
+		if err == nil || errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		mu.logger.WarnContext(ctx, "não foi possível remover o arquivo", "key", key, "error", err)
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func newMediaKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// cleanFilename keeps the last element of a client-supplied name, without
+// control characters, for display and downloads only.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+
+	if name == "." || name == "/" || strings.TrimSpace(name) == "" {
+		return "upload"
+	}
+	if runes := []rune(name); len(runes) > maxFilenameLength {
+		name = string(runes[:maxFilenameLength])
+	}
+	return name
+}
+
+type byteCounter struct {
+	n int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
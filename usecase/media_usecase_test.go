@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+	"github.com/juanplagos/bubble/storage"
+)
+
+type mockMediaRepo struct {
+	media     model.Media
+	created   *model.Media
+	deleted   bool
+	err       error
+	createErr error
+}
+
+func (m *mockMediaRepo) GetMediaByOwner(ctx context.Context, owner string) ([]model.Media, error) {
+	return []model.Media{m.media}, m.err
+}
+
+func (m *mockMediaRepo) GetMedia(ctx context.Context, id int) (model.Media, error) {
+	return m.media, m.err
+}
+
+func (m *mockMediaRepo) CreateMedia(ctx context.Context, media *model.Media) error {
+	m.created = media
+	return m.createErr
+}
+
+func (m *mockMediaRepo) DeleteMedia(ctx context.Context, id int) error {
+	m.deleted = true
+	return m.err
+}
+
+type memStorage struct {
+	files map[string][]byte
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{files: map[string][]byte{}}
+}
+
+func (s *memStorage) Put(key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.files[key] = data
+	return nil
+}
+
+func (s *memStorage) Open(key string) (io.ReadSeekCloser, error) {
+	data, ok := s.files[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return nopCloser{bytes.NewReader(data)}, nil
+}
+
+func (s *memStorage) Delete(key string) error {
+	if _, ok := s.files[key]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(s.files, key)
+	return nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error { return nil }
+
+var pdf = []byte("%PDF-1.7\n% a document\n%%EOF\n")
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMediaUseCase_Upload(t *testing.T) {
+	t.Run("document", func(t *testing.T) {
+		repo, files := &mockMediaRepo{}, newMemStorage()
+		uc := NewMediaUseCase(repo, files, nil)
+
+		media, err := uc.Upload(context.Background(), owner, `C:\docs\artigo.pdf`, bytes.NewReader(pdf))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if media.ContentType != "application/pdf" || media.Size != int64(len(pdf)) || media.Owner != "author" || media.Filename != "artigo.pdf" {
+			t.Errorf("Unexpected media %+v", media)
+		}
+
+		if len(media.Checksum) != 64 || !bytes.Equal(files.files[media.Key], pdf) || len(media.Variants) != 0 {
+			t.Errorf("Expected the file stored as is under its key, got %+v", media)
+		}
+	})
+
+	t.Run("image", func(t *testing.T) {
+		repo, files := &mockMediaRepo{}, newMemStorage()
+		uc := NewMediaUseCase(repo, files, nil)
+
+		media, err := uc.Upload(context.Background(), owner, "gato.png", bytes.NewReader(testPNG(t, 1000, 500)))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if media.Width != 1000 || media.Height != 500 || len(media.Variants) != 2 {
+			t.Fatalf("Expected dimensions and two variants, got %+v", media)
+		}
+
+		thumb := media.Variants[0]
+		if thumb.Name != "thumbnail" || thumb.Width != 320 || thumb.Key != media.Key+"-thumbnail" || int64(len(files.files[thumb.Key])) != thumb.Size {
+			t.Errorf("Expected the thumbnail stored next to the original, got %+v", thumb)
+		}
+
+		if len(files.files) != 3 || repo.created == nil {
+			t.Errorf("Expected original and variants stored, got %d files", len(files.files))
+		}
+	})
+
+	t.Run("broken image", func(t *testing.T) {
+		files := newMemStorage()
+		uc := NewMediaUseCase(&mockMediaRepo{}, files, nil)
+
+		broken := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 100)...)
+		var validationErr *ValidationError
+		if _, err := uc.Upload(context.Background(), owner, "x.png", bytes.NewReader(broken)); !errors.As(err, &validationErr) {
+			t.Errorf("Expected ValidationError, got %v", err)
+		}
+
+		if len(files.files) != 0 {
+			t.Error("Expected nothing stored")
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		files := newMemStorage()
+		uc := NewMediaUseCase(&mockMediaRepo{}, files, nil)
+
+		_, err := uc.Upload(context.Background(), owner, "x.svg", strings.NewReader(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`))
+		if !errors.Is(err, ErrUnsupportedMedia) {
+			t.Errorf("Expected ErrUnsupportedMedia, got %v", err)
+		}
+
+		if len(files.files) != 0 {
+			t.Error("Expected nothing stored")
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		files := newMemStorage()
+		uc := NewMediaUseCase(&mockMediaRepo{}, files, nil)
+
+		for name, head := range map[string][]byte{"big.pdf": pdf, "big.png": testPNG(t, 10, 10)} {
+			body := io.MultiReader(bytes.NewReader(head), bytes.NewReader(make([]byte, MaxMediaSize)))
+			if _, err := uc.Upload(context.Background(), owner, name, body); !errors.Is(err, ErrTooLarge) {
+				t.Errorf("Expected ErrTooLarge for %s, got %v", name, err)
+			}
+		}
+
+		if len(files.files) != 0 {
+			t.Error("Expected the partial file to be removed")
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		uc := NewMediaUseCase(&mockMediaRepo{}, newMemStorage(), nil)
+
+		var validationErr *ValidationError
+		if _, err := uc.Upload(context.Background(), owner, "x.png", strings.NewReader("")); !errors.As(err, &validationErr) {
+			t.Errorf("Expected ValidationError, got %v", err)
+		}
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		uc := NewMediaUseCase(&mockMediaRepo{}, newMemStorage(), nil)
+
+		if _, err := uc.Upload(context.Background(), model.Author{Username: "r", Role: model.RoleReader}, "x.pdf", bytes.NewReader(pdf)); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+	})
+
+	t.Run("record fails", func(t *testing.T) {
+		files := newMemStorage()
+		uc := NewMediaUseCase(&mockMediaRepo{createErr: repository.ErrUnavailable}, files, nil)
+
+		if _, err := uc.Upload(context.Background(), owner, "x.png", bytes.NewReader(testPNG(t, 1000, 500))); !errors.Is(err, repository.ErrUnavailable) {
+			t.Errorf("Expected ErrUnavailable, got %v", err)
+		}
+
+		if len(files.files) != 0 {
+			t.Error("Expected the stored files to be removed")
+		}
+	})
+}
+
+func TestMediaUseCase_GetMedia(t *testing.T) {
+	files := newMemStorage()
+	files.files["k"] = []byte("original")
+	files.files["k-thumbnail"] = []byte("thumbnail")
+	stored := model.Media{
+		ID:          1,
+		ContentType: "image/webp",
+		Key:         "k",
+		Variants:    []model.MediaVariant{{Name: "thumbnail", ContentType: "image/jpeg", Width: 320, Key: "k-thumbnail"}},
+	}
+
+	t.Run("original", func(t *testing.T) {
+		uc := NewMediaUseCase(&mockMediaRepo{media: stored}, files, nil)
+
+		_, f, err := uc.GetMedia(context.Background(), 1, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer f.Close()
+
+		if data, _ := io.ReadAll(f); string(data) != "original" {
+			t.Errorf("Expected the original, got %q", data)
+		}
+	})
+
+	t.Run("variant", func(t *testing.T) {
+		uc := NewMediaUseCase(&mockMediaRepo{media: stored}, files, nil)
+
+		media, f, err := uc.GetMedia(context.Background(), 1, "thumbnail")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer f.Close()
+
+		if data, _ := io.ReadAll(f); string(data) != "thumbnail" || media.ContentType != "image/jpeg" || media.Width != 320 {
+			t.Errorf("Expected the thumbnail, got %q %+v", data, media)
+		}
+	})
+
+	t.Run("unknown variant", func(t *testing.T) {
+		uc := NewMediaUseCase(&mockMediaRepo{media: stored}, files, nil)
+
+		if _, _, err := uc.GetMedia(context.Background(), 1, "huge"); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		uc := NewMediaUseCase(&mockMediaRepo{media: model.Media{ID: 1, Key: "gone"}}, files, nil)
+
+		if _, _, err := uc.GetMedia(context.Background(), 1, ""); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestMediaUseCase_DeleteMedia(t *testing.T) {
+	t.Run("owner", func(t *testing.T) {
+		files := newMemStorage()
+		files.files["k"] = []byte("original")
+		files.files["k-thumbnail"] = []byte("thumbnail")
+		repo := &mockMediaRepo{media: model.Media{ID: 1, Owner: "author", Key: "k", Variants: []model.MediaVariant{{Name: "thumbnail", Key: "k-thumbnail"}}}}
+		uc := NewMediaUseCase(repo, files, nil)
+
+		if err := uc.DeleteMedia(context.Background(), owner, 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if !repo.deleted || len(files.files) != 0 {
+			t.Error("Expected record and files removed")
+		}
+	})
+
+	t.Run("someone else's", func(t *testing.T) {
+		repo := &mockMediaRepo{media: model.Media{ID: 1, Owner: "other", Key: "k"}}
+		uc := NewMediaUseCase(repo, newMemStorage(), nil)
+
+		if err := uc.DeleteMedia(context.Background(), owner, 1); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden, got %v", err)
+		}
+
+		if repo.deleted {
+			t.Error("Expected the record kept")
+		}
+	})
+}
+
+func TestCleanFilename(t *testing.T) {
+	tests := map[string]string{
+		"foto.png":               "foto.png",
+		"../../etc/passwd":       "passwd",
+		`C:\Users\a\b.jpg`:       "b.jpg",
+		"a\x00b\n.png":           "ab.png",
+		"":                       "upload",
+		"/":                      "upload",
+		strings.Repeat("é", 300): strings.Repeat("é", maxFilenameLength),
+	}
+
+	for in, want := range tests {
+		if got := cleanFilename(in); got != want {
+			t.Errorf("cleanFilename(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
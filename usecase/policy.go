@@ -0,0 +1,47 @@
+package usecase
+
+import "github.com/juanplagos/bubble/model"
+
+type Permission string
+
+const (
+	PermManageAuthors  Permission = "authors:manage"
+	PermCreateEntries  Permission = "entries:create"
+	PermEditOwnEntries Permission = "entries:edit-own"
+	PermEditAnyEntries Permission = "entries:edit-any"
+	PermModerate       Permission = "comments:moderate"
+)
+
+// rolePermissions is the single source of truth for what each role may do.
+// Reading published content needs no permission at all.
+var rolePermissions = map[model.Role][]Permission{
+	model.RoleAdmin:  {PermManageAuthors, PermCreateEntries, PermEditOwnEntries, PermEditAnyEntries, PermModerate},
+	model.RoleEditor: {PermCreateEntries, PermEditOwnEntries, PermEditAnyEntries, PermModerate},
+	model.RoleWriter: {PermCreateEntries, PermEditOwnEntries},
+	model.RoleReader: {},
+}
+
+func Can(author model.Author, perm Permission) bool {
+	for _, p := range rolePermissions[author.Role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+func canEditEntry(author model.Author, entry model.Entry) bool {
+	if Can(author, PermEditAnyEntries) {
+		return true
+	}
+	return entry.Author == author.Username && Can(author, PermEditOwnEntries)
+}
+
+// canManageMedia lets authors remove their own uploads and editors remove
+// anyone's, mirroring the rules for entries.
+func canManageMedia(author model.Author, media model.Media) bool {
+	if Can(author, PermEditAnyEntries) {
+		return true
+	}
+	return media.Owner == author.Username && Can(author, PermEditOwnEntries)
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+func TestCanEditEntry(t *testing.T) {
+	own := model.Entry{ID: 1, Author: "me"}
+	other := model.Entry{ID: 2, Author: "someone-else"}
+
+	tests := []struct {
+		role      model.Role
+		own       bool
+		other     bool
+		canCreate bool
+		moderate  bool
+	}{
+		{model.RoleAdmin, true, true, true, true},
+		{model.RoleEditor, true, true, true, true},
+		{model.RoleWriter, true, false, true, false},
+		{model.RoleReader, false, false, false, false},
+		{"", false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			caller := model.Author{Username: "me", Role: tt.role}
+
+			if got := canEditEntry(caller, own); got != tt.own {
+				t.Errorf("Expected own entry editable=%v, got %v", tt.own, got)
+			}
+
+			if got := canEditEntry(caller, other); got != tt.other {
+				t.Errorf("Expected other entry editable=%v, got %v", tt.other, got)
+			}
+
+			if got := Can(caller, PermCreateEntries); got != tt.canCreate {
+				t.Errorf("Expected create=%v, got %v", tt.canCreate, got)
+			}
+
+			if got := Can(caller, PermModerate); got != tt.moderate {
+				t.Errorf("Expected moderate=%v, got %v", tt.moderate, got)
+			}
+		})
+	}
+}
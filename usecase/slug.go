@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Generated slugs leave room for a numeric suffix within maxSlugLength.
+const maxGeneratedSlugLength = maxSlugLength - 10
+
+// Letters that do not decompose into a base letter plus accents.
+var slugTransliterations = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "Æ", "ae", "œ", "oe", "Œ", "oe",
+	"ø", "o", "Ø", "o", "đ", "d", "Đ", "d", "ł", "l", "Ł", "l", "þ", "th", "Þ", "th",
+)
+
+// slugify turns a title into a URL-safe slug, transliterating accented
+// letters ("ação" becomes "acao") and joining words with hyphens. Anything
+// that is not an ASCII letter or digit afterwards separates words.
+func slugify(title string) string {
+	stripMarks := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)))
+	s, _, err := transform.String(stripMarks, slugTransliterations.Replace(title))
+	if err != nil {
+		s = title
+	}
+
+	var b strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(s) {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			separate = true
+			continue
+		}
+		if separate && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		separate = false
+		b.WriteRune(r)
+	}
+
+	slug := b.String()
+	if len(slug) > maxGeneratedSlugLength {
+		slug = slug[:maxGeneratedSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+		slug = strings.TrimRight(slug, "-")
+	}
+	return slug
+}
+
+// uniqueSlug returns base, or base with the lowest numeric suffix from 2
+// upwards, whichever is not already taken.
+func uniqueSlug(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, s := range taken {
+		used[s] = true
+	}
+
+	slug := base
+	for n := 2; used[slug]; n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	return slug
+}
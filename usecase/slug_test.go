@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Hello World", "hello-world"},
+		{"Ação e reação", "acao-e-reacao"},
+		{"  Über   straße!  ", "uber-strasse"},
+		{"Go 1.25: o que há de novo?", "go-1-25-o-que-ha-de-novo"},
+		{"Crème brûlée & Œuvres", "creme-brulee-oeuvres"},
+		{"---", ""},
+		{"日本語", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := slugify(tt.title); got != tt.want {
+				t.Errorf("slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("long title", func(t *testing.T) {
+		got := slugify(strings.Repeat("palavra ", 40))
+
+		if len(got) > maxGeneratedSlugLength {
+			t.Errorf("Expected at most %d characters, got %d", maxGeneratedSlugLength, len(got))
+		}
+
+		if !slugPattern.MatchString(got) {
+			t.Errorf("Expected a valid slug, got %q", got)
+		}
+	})
+}
+
+func TestUniqueSlug(t *testing.T) {
+	tests := []struct {
+		name  string
+		taken []string
+		want  string
+	}{
+		{"free", nil, "post"},
+		{"taken", []string{"post"}, "post-2"},
+		{"gap", []string{"post", "post-2", "post-4"}, "post-3"},
+		{"only suffixed taken", []string{"post-2"}, "post"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uniqueSlug("post", tt.taken); got != tt.want {
+				t.Errorf("uniqueSlug(%v) = %q, want %q", tt.taken, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+	"github.com/juanplagos/bubble/model"
+	"github.com/juanplagos/bubble/repository"
+)
+
+type tagUseCase struct {
+	repo repository.TagRepo
+}
+
+func NewTagUseCase(repo repository.TagRepo) TagUseCase {
+	return &tagUseCase{
+		repo: repo,
+	}
+}
+
+func (tu *tagUseCase) GetAllTags(ctx context.Context) ([]model.Tag, error) {
+	return tu.repo.GetAllTags(ctx)
+}
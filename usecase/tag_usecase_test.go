@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+type mockTagRepo struct {
+	tags []model.Tag
+	err  error
+}
+
+func (m *mockTagRepo) GetAllTags(ctx context.Context) ([]model.Tag, error) {
+	return m.tags, m.err
+}
+
+func TestTagUseCase_GetAllTags(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		uc := NewTagUseCase(&mockTagRepo{tags: []model.Tag{{Name: "go", Count: 2}}})
+
+		tags, err := uc.GetAllTags(context.Background())
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		if len(tags) != 1 {
+			t.Errorf("Expected 1 tag, got %d", len(tags))
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		uc := NewTagUseCase(&mockTagRepo{err: errors.New("database error")})
+
+		if _, err := uc.GetAllTags(context.Background()); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/juanplagos/bubble/model"
+)
+
+const (
+	maxTitleLength    = 200
+	maxSlugLength     = 200
+	maxBodyLength     = 100000
+	maxTags           = 10
+	maxTagLength      = 50
+	minUsernameLength = 3
+	maxUsernameLength = 32
+	maxEmailLength    = 254
+	minPasswordLength = 8
+	// bcrypt ignores everything past 72 bytes.
+	maxPasswordBytes = 72
+
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+
+	maxSearchLength = 200
+
+	maxCommentNameLength = 100
+	maxCommentLength     = 5000
+)
+
+var (
+	slugPattern     = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+		return false
+	}
+	return true
+}
+
+func (v *validator) maxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		v.add(field, "must be at most %d characters", max)
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
+
+func validateEntry(entry *model.Entry, now time.Time) error {
+	var v validator
+
+	if v.required("title", entry.Title) {
+		v.maxLength("title", entry.Title, maxTitleLength)
+	}
+
+	if v.required("slug", entry.Slug) {
+		v.maxLength("slug", entry.Slug, maxSlugLength)
+		if !slugPattern.MatchString(entry.Slug) {
+			v.add("slug", "must contain only lowercase letters, digits and single hyphens")
+		}
+	}
+
+	if v.required("body", entry.Body) {
+		v.maxLength("body", entry.Body, maxBodyLength)
+	}
+
+	if !entry.BodyFormat.Valid() {
+		v.add("body_format", "must be one of markdown, html or plain")
+	}
+
+	validateTags(&v, "tags", entry.Tags)
+
+	switch entry.Status {
+	case model.StatusScheduled:
+		if entry.PublishedAt == nil || !entry.PublishedAt.After(now) {
+			v.add("published_at", "must be in the future for scheduled entries")
+		}
+	case model.StatusPublished:
+		if entry.PublishedAt != nil && entry.PublishedAt.After(now) {
+			v.add("published_at", "must not be in the future for published entries; use the scheduled status")
+		}
+	case model.StatusDraft, model.StatusArchived:
+	default:
+		v.add("status", "must be one of draft, published, scheduled or archived")
+	}
+
+	return v.err()
+}
+
+// normalizeTags puts tag names in slug form and drops duplicates, keeping
+// the order they were given in. Names with nothing usable in them become
+// empty strings for validateTags to report.
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, t := range tags {
+		name := slugify(t)
+		if name != "" && seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+func validateTags(v *validator, field string, tags []string) {
+	if len(tags) > maxTags {
+		v.add(field, "must have at most %d tags", maxTags)
+	}
+	for _, t := range tags {
+		if t == "" {
+			v.add(field, "must contain letters or digits in every tag")
+			return
+		}
+		if len(t) > maxTagLength {
+			v.add(field, "must be at most %d characters per tag", maxTagLength)
+			return
+		}
+	}
+}
+
+// validateAuthor checks an author about to be stored. The password is only
+// checked when present, since updates may leave it unchanged.
+func validateAuthor(author *model.Author, requirePassword bool) error {
+	var v validator
+
+	if v.required("username", author.Username) {
+		n := utf8.RuneCountInString(author.Username)
+		if n < minUsernameLength || n > maxUsernameLength {
+			v.add("username", "must be between %d and %d characters", minUsernameLength, maxUsernameLength)
+		}
+		if !usernamePattern.MatchString(author.Username) {
+			v.add("username", "must contain only letters, digits, dots, hyphens and underscores")
+		}
+	}
+
+	if v.required("email", author.Email) {
+		v.maxLength("email", author.Email, maxEmailLength)
+		if addr, err := mail.ParseAddress(author.Email); err != nil || addr.Address != author.Email {
+			v.add("email", "must be a valid email address")
+		}
+	}
+
+	if author.Password != "" || requirePassword {
+		validatePassword(&v, author.Password)
+	}
+
+	if !author.Role.Valid() {
+		v.add("role", "must be one of admin, editor, writer or reader")
+	}
+
+	return v.err()
+}
+
+func validatePassword(v *validator, password string) {
+	if !v.required("password", password) {
+		return
+	}
+
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		v.add("password", "must be at least %d characters", minPasswordLength)
+	}
+	if len(password) > maxPasswordBytes {
+		v.add("password", "must be at most %d bytes", maxPasswordBytes)
+	}
+
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	if !letter || !digit {
+		v.add("password", "must contain at least one letter and one digit")
+	}
+}
+
+func validateComment(comment *model.Comment) error {
+	var v validator
+
+	if v.required("author_name", comment.AuthorName) {
+		v.maxLength("author_name", comment.AuthorName, maxCommentNameLength)
+	}
+
+	if v.required("author_email", comment.AuthorEmail) {
+		v.maxLength("author_email", comment.AuthorEmail, maxEmailLength)
+		if addr, err := mail.ParseAddress(comment.AuthorEmail); err != nil || addr.Address != comment.AuthorEmail {
+			v.add("author_email", "must be a valid email address")
+		}
+	}
+
+	if v.required("body", comment.Body) {
+		v.maxLength("body", comment.Body, maxCommentLength)
+	}
+
+	return v.err()
+}
+
+// normalizeEntryQuery fills in defaults and rejects values the repository
+// cannot honour.
+func normalizeEntryQuery(q model.EntryQuery) (model.EntryQuery, error) {
+	var v validator
+
+	switch {
+	case q.Limit == 0:
+		q.Limit = DefaultPageSize
+	case q.Limit < 0 || q.Limit > MaxPageSize:
+		v.add("limit", "must be between 1 and %d", MaxPageSize)
+	}
+
+	if q.Offset < 0 {
+		v.add("offset", "must not be negative")
+	}
+	if q.Cursor != "" && q.Offset != 0 {
+		v.add("offset", "cannot be combined with cursor")
+	}
+
+	switch {
+	case q.Status == "":
+		q.Status = model.StatusPublished
+	case !q.Status.Valid():
+		v.add("status", "must be one of draft, published, scheduled or archived")
+	}
+
+	switch q.Sort {
+	case "":
+		q.Sort = model.SortByCreatedAt
+	case model.SortByCreatedAt, model.SortByTitle:
+	case model.SortByPublishedAt:
+		// Only published entries are guaranteed a publication date to page by.
+		if q.Status != model.StatusPublished {
+			v.add("sort", "can only be published_at when listing published entries")
+		}
+	default:
+		v.add("sort", "must be one of created_at, published_at or title")
+	}
+
+	switch q.Order {
+	case "":
+		q.Order = model.Descending
+		if q.Sort == model.SortByTitle {
+			q.Order = model.Ascending
+		}
+	case model.Ascending, model.Descending:
+	default:
+		v.add("order", "must be asc or desc")
+	}
+
+	q.Tags = normalizeTags(q.Tags)
+	validateTags(&v, "tag", q.Tags)
+
+	switch q.TagMatch {
+	case "":
+		q.TagMatch = model.MatchAllTags
+	case model.MatchAllTags, model.MatchAnyTag:
+	default:
+		v.add("tag_match", "must be all or any")
+	}
+
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		v.add("to", "must be after from")
+	}
+
+	return q, v.err()
+}
+
+func normalizeCommentQuery(q model.CommentQuery) (model.CommentQuery, error) {
+	var v validator
+
+	switch {
+	case q.Status == "":
+		q.Status = model.CommentPending
+	case !q.Status.Valid():
+		v.add("status", "must be one of pending, approved or spam")
+	}
+
+	switch {
+	case q.Limit == 0:
+		q.Limit = DefaultPageSize
+	case q.Limit < 0 || q.Limit > MaxPageSize:
+		v.add("limit", "must be between 1 and %d", MaxPageSize)
+	}
+
+	if q.Offset < 0 {
+		v.add("offset", "must not be negative")
+	}
+
+	return q, v.err()
+}
+
+func normalizeSearchQuery(q model.SearchQuery) (model.SearchQuery, error) {
+	var v validator
+
+	q.Text = strings.TrimSpace(q.Text)
+	if v.required("q", q.Text) {
+		v.maxLength("q", q.Text, maxSearchLength)
+	}
+
+	switch {
+	case q.Limit == 0:
+		q.Limit = DefaultPageSize
+	case q.Limit < 0 || q.Limit > MaxPageSize:
+		v.add("limit", "must be between 1 and %d", MaxPageSize)
+	}
+
+	if q.Offset < 0 {
+		v.add("offset", "must not be negative")
+	}
+
+	return q, v.err()
+}